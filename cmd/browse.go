@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var browseOpen bool
+
+// splitLine separates a trailing :line from a file argument
+func splitLine(arg string) (string, int) {
+	if i := strings.LastIndex(arg, ":"); i > 0 {
+		if line, err := strconv.Atoi(arg[i+1:]); err == nil && line > 0 {
+			return arg[:i], line
+		}
+	}
+	return arg, 0
+}
+
+// repoRelative makes file relative to the repo root when it names an existing path inside the repo,
+// so paths typed from a subdirectory work; anything else is taken as relative to the root already
+func repoRelative(repoPath, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(repoPath, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+	return file
+}
+
+// openCommand returns the system command that opens a URL in the browser
+func openCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "explorer"
+	}
+	return "xdg-open"
+}
+
+var browseCmd = &cobra.Command{
+	Use:   "browse <repo-name> [path[:line]]",
+	Short: "Print or open a repository's web page",
+	Long:  "Builds the web URL of a repository from its origin remote at the current branch, optionally pointing at a file and line. Use . for the repository containing the working directory.",
+	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completeRepoNames(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		repoName := args[0]
+		var repoPath string
+		if repoName == "." {
+			repoPath = findRepoOrExit(nil).Path
+		} else {
+			path, err := core.ResolveRepo(repoName)
+			if err != nil {
+				exitWithResolveError(repoName, err)
+			}
+			repoPath = path
+		}
+
+		var file string
+		var line int
+		if len(args) > 1 {
+			file, line = splitLine(args[1])
+			file = repoRelative(repoPath, file)
+		}
+
+		webURL, err := core.BrowseURL(context.Background(), repoPath, file, line)
+		if err != nil {
+			fmt.Printf("Error building URL: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !browseOpen {
+			fmt.Println(webURL)
+			return
+		}
+		if err := exec.Command(openCommand(), webURL).Start(); err != nil {
+			fmt.Printf("Error opening %s: %v\n", webURL, err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	browseCmd.Flags().BoolVarP(&browseOpen, "open", "o", false, "open the URL in the browser instead of printing it")
+	browseCmd.Flags().BoolVar(&jsonErrors, "json", false, "print lookup errors as JSON on stderr")
+	RootCmd.AddCommand(browseCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective config for this machine",
+	Long:  "Prints the config after applying includes and matching hosts sections, annotating each value with the file it came from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := core.EffectiveConfigYaml()
+		if err != nil {
+			fmt.Printf("Error rendering config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(data))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	RootCmd.AddCommand(configCmd)
+}
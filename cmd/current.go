@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var currentShort bool
+
+// findRepoOrExit maps the path argument, or the working directory, to its indexed repo
+func findRepoOrExit(args []string) *core.RepoInfo {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	info, err := core.FindRepo(path)
+	if err != nil {
+		exitWithLookupError(lookupError{Error: "not_found", Message: err.Error()}, ExitNotFound)
+	}
+	return info
+}
+
+var currentCmd = &cobra.Command{
+	Use:     "current [path]",
+	Aliases: []string{"which"},
+	Short:   "Show the indexed repository containing a path",
+	Long:    "Maps the working directory, or the given path, back to the indexed repository that contains it, for shell prompts and status lines",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		info := findRepoOrExit(args)
+
+		switch {
+		case jsonErrors:
+			data, err := json.Marshal(info)
+			if err != nil {
+				fmt.Printf("Error encoding repository: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		case currentShort:
+			fmt.Println(info.Name)
+		default:
+			fmt.Printf("name:   %s\n", info.Name)
+			fmt.Printf("path:   %s\n", info.Path)
+			fmt.Printf("root:   %s\n", info.Root)
+			if len(info.Groups) > 0 {
+				fmt.Printf("groups: %s\n", strings.Join(info.Groups, ", "))
+			}
+			if len(info.Tags) > 0 {
+				fmt.Printf("tags:   %s\n", strings.Join(info.Tags, ", "))
+			}
+			if info.Description != "" {
+				fmt.Printf("about:  %s\n", info.Description)
+			}
+		}
+	},
+}
+
+var rootDirCmd = &cobra.Command{
+	Use:   "root [path]",
+	Short: "Print the top directory of the current repository",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(findRepoOrExit(args).Path)
+	},
+}
+
+func init() {
+	currentCmd.Flags().BoolVarP(&currentShort, "short", "s", false, "print only the repository name")
+	currentCmd.Flags().BoolVar(&jsonErrors, "json", false, "print the repository, or the lookup error, as JSON")
+	rootDirCmd.Flags().BoolVar(&jsonErrors, "json", false, "print lookup errors as JSON on stderr")
+	RootCmd.AddCommand(currentCmd, rootDirCmd)
+}
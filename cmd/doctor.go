@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var doctorExplain string
+
+var doctorCmd = &cobra.Command{
+	Use:         "doctor",
+	Short:       "Check config, roots and cache health",
+	Long:        "Validates each step of loading: the config parses, every root is a readable directory, roots do not nest, repo names are unique and not taken by a subcommand, the cache is valid and shell integration is installed",
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{skipLoadAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if doctorExplain != "" {
+			if err := core.ResolvePaths(configPath); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := core.LoadConfig(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			explanation, err := core.ExplainPath(doctorExplain)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(explanation)
+			return
+		}
+
+		checks := core.RunDoctor(configPath, commandNames())
+
+		var failed int
+		for _, c := range checks {
+			fmt.Printf("[%-4s] %s: %s\n", c.Status, c.Name, c.Message)
+			if c.Hint != "" {
+				fmt.Printf("       fix: %s\n", c.Hint)
+			}
+			if c.Status == core.CheckFail {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorExplain, "explain", "", "explain whether a directory is indexed and why it is excluded")
+	RootCmd.AddCommand(doctorCmd)
+}
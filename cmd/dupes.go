@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var dupesJobs int
+
+// ago renders how long ago t was, to the largest whole unit
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%dmo ago", int(d.Hours()/24/30))
+}
+
+var dupesCmd = &cobra.Command{
+	Use:   "dupes",
+	Short: "Find repositories cloned more than once",
+	Long:  "Groups indexed repositories by normalised origin URL, placing copies without an origin by root commit, and shows each copy's branch, dirty state and last activity, most recent first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups := core.FindDupes(context.Background(), dupesJobs)
+		if len(groups) == 0 {
+			fmt.Println("No duplicate clones found.")
+			return
+		}
+
+		for _, group := range groups {
+			switch {
+			case group.Remote != "" && group.RootCommit != "":
+				fmt.Printf("%s (root %.7s)\n", group.Remote, group.RootCommit)
+			case group.Remote != "":
+				fmt.Println(group.Remote)
+			case group.RootCommit != "":
+				fmt.Printf("root %.7s\n", group.RootCommit)
+			default:
+				fmt.Println("related history")
+			}
+
+			for _, c := range group.Copies {
+				if c.Err != nil {
+					fmt.Printf("  %-50s error: %v\n", c.Path, c.Err)
+					continue
+				}
+				state := "clean"
+				if c.Dirty {
+					state = "dirty"
+				}
+				fmt.Printf("  %-50s %-20s %-6s %s\n", c.Path, c.Branch, state, ago(c.LastActivity))
+			}
+		}
+		fmt.Printf("%d repositories cloned more than once\n", len(groups))
+	},
+}
+
+func init() {
+	dupesCmd.Flags().IntVarP(&dupesJobs, "jobs", "j", runtime.NumCPU(), "number of repositories to inspect in parallel")
+	RootCmd.AddCommand(dupesCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+)
+
+// Exit codes let shell wrappers and editor plugins tell lookup failures apart
+const (
+	ExitNotFound  = 2
+	ExitAmbiguous = 3
+	ExitConfig    = 4
+)
+
+var jsonErrors bool
+
+// lookupError is the machine-readable form of a failed lookup
+type lookupError struct {
+	Error       string   `json:"error"`
+	Name        string   `json:"name,omitempty"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Candidates  []string `json:"candidates,omitempty"`
+}
+
+// ExitCode maps a command error to the process exit code
+func ExitCode(err error) int {
+	var configErr *core.ConfigError
+	if errors.As(err, &configErr) {
+		return ExitConfig
+	}
+	return 1
+}
+
+// exitWithLookupError prints the error to stderr, as JSON when --json is set, and exits with code
+func exitWithLookupError(e lookupError, code int) {
+	if jsonErrors {
+		data, _ := json.Marshal(e)
+		fmt.Fprintln(os.Stderr, string(data))
+		os.Exit(code)
+	}
+
+	fmt.Fprintln(os.Stderr, e.Message)
+	if len(e.Suggestions) > 0 {
+		fmt.Fprintln(os.Stderr, "Did you mean:")
+		for _, s := range e.Suggestions {
+			fmt.Fprintf(os.Stderr, "  %s\n", s)
+		}
+	}
+	for _, c := range e.Candidates {
+		fmt.Fprintf(os.Stderr, "  %s\n", c)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	execGroup    string
+	execFilter   string
+	execJobs     int
+	execFailFast bool
+	execCollect  bool
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] -- command [args...]",
+	Short: "Run a command in every matching repository",
+	Long:  "Runs a command inside each indexed repository in parallel and prints a summary of exit codes",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repos, err := core.SelectRepos(execGroup, execFilter)
+		if err != nil {
+			fmt.Printf("Error selecting repositories: %v\n", err)
+			os.Exit(1)
+		}
+		if len(repos) == 0 {
+			fmt.Println("No repositories matched.")
+			return
+		}
+
+		opts := core.ExecOptions{Jobs: execJobs, FailFast: execFailFast}
+		if !execCollect {
+			opts.Output = os.Stdout
+		}
+		results := core.ExecInRepos(context.Background(), repos, args, opts)
+
+		var succeeded, failed, skipped int
+		for _, r := range results {
+			if execCollect && !r.Skipped {
+				fmt.Printf("==> %s (%s)\n%s", r.Name, r.Path, r.Output)
+			}
+			switch {
+			case r.Skipped:
+				skipped++
+			case r.ExitCode == 0:
+				succeeded++
+			default:
+				failed++
+			}
+		}
+
+		fmt.Println()
+		for _, r := range results {
+			switch {
+			case r.Skipped:
+				fmt.Printf("  %-30s skipped\n", r.Name)
+			case r.Err != nil:
+				fmt.Printf("  %-30s error: %v\n", r.Name, r.Err)
+			case r.ExitCode != 0:
+				fmt.Printf("  %-30s exit %d\n", r.Name, r.ExitCode)
+			}
+		}
+		fmt.Printf("%d succeeded, %d failed, %d skipped\n", succeeded, failed, skipped)
+
+		if failed > 0 || skipped > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().StringVarP(&execGroup, "group", "g", "", "only run in repositories of this config group")
+	execCmd.Flags().StringVarP(&execFilter, "filter", "f", "", "only run in repositories whose name matches this glob")
+	execCmd.Flags().IntVarP(&execJobs, "jobs", "j", runtime.NumCPU(), "number of repositories to run in parallel")
+	execCmd.Flags().BoolVar(&execFailFast, "fail-fast", false, "cancel remaining commands after the first failure")
+	execCmd.Flags().BoolVar(&execCollect, "collect", false, "print each repository's output as a block instead of prefixing lines")
+	RootCmd.AddCommand(execCmd)
+}
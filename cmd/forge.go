@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var forgeUncloned bool
+
+var forgeCmd = &cobra.Command{
+	Use:   "forge",
+	Short: "List repositories on GitHub, GitLab or Gitea",
+}
+
+var forgeListCmd = &cobra.Command{
+	Use:   "list [forge]",
+	Short: "List the repositories of configured forges",
+	Long:  "Lists the repositories of every configured forge, or the named one, marking those already cloned. The listing is cached so uncloned repositories complete as names and are cloned when picked.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		repos, err := core.ListForgeRepos(context.Background(), name)
+		if err != nil {
+			fmt.Printf("Error listing forge repositories: %v\n", err)
+		}
+
+		var cloned int
+		for _, repo := range repos {
+			if repo.LocalPath != "" {
+				cloned++
+				if forgeUncloned {
+					continue
+				}
+			}
+			mark := " "
+			if repo.LocalPath != "" {
+				mark = "*"
+			}
+			note := repo.LocalPath
+			if repo.Archived {
+				note += " (archived)"
+			}
+			fmt.Printf("%s %-10s %-40s %s\n", mark, repo.Forge, repo.FullName, note)
+		}
+		fmt.Printf("%d repositories, %d cloned\n", len(repos), cloned)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	forgeListCmd.Flags().BoolVarP(&forgeUncloned, "uncloned", "u", false, "only list repositories that are not cloned")
+	forgeCmd.AddCommand(forgeListCmd)
+	RootCmd.AddCommand(forgeCmd)
+}
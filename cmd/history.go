@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var historyLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recent repository switches",
+	Long:  "Lists recent switches, most recent first, numbered for `repo-switcher -N`",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := core.History()
+		if err != nil {
+			fmt.Printf("Error reading history: %v\n", err)
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No history yet.")
+			return
+		}
+
+		for i, entry := range entries {
+			if historyLimit > 0 && i >= historyLimit {
+				break
+			}
+			fmt.Printf("%4d  %s  %s\n", -i, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Name)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "number of entries to show, 0 for all")
+	RootCmd.AddCommand(historyCmd)
+}
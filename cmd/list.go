@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var listLong bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List indexed repositories",
+	Long:  "Lists repository names in the order completion offers them, set by sort in config",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range core.ReposName {
+			if listLong {
+				fmt.Printf("%-40s %s\n", name, core.ReposMap[name])
+			} else {
+				fmt.Println(name)
+			}
+		}
+	},
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "also print each repository's path")
+	RootCmd.AddCommand(listCmd)
+}
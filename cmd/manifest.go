@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	manifestJSON        bool
+	manifestRestoreJobs int
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Export and restore the repository layout across machines",
+}
+
+var manifestExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Write every indexed repository to a manifest",
+	Long:  "Writes each repository's path relative to its root, remotes and default branch. The format follows the file extension (.json or YAML); without a file the manifest is printed to stdout.",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := core.BuildManifest(context.Background())
+		if err != nil {
+			fmt.Printf("Error building manifest: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) == 0 {
+			data, err := core.MarshalManifest(manifest, manifestJSON)
+			if err != nil {
+				fmt.Printf("Error encoding manifest: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Print(string(data))
+			return
+		}
+
+		if err := core.WriteManifest(manifest, args[0]); err != nil {
+			fmt.Printf("Error writing manifest: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d repositories to %s\n", len(manifest.Repos), args[0])
+	},
+}
+
+var manifestRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Clone repositories from a manifest that are missing on this machine",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest, err := core.ReadManifest(args[0])
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := core.RestoreManifest(context.Background(), manifest, manifestRestoreJobs)
+
+		var cloned, failed, present, noRemote int
+		for _, r := range results {
+			switch r.Status {
+			case core.RestorePresent:
+				present++
+				continue
+			case core.RestoreCloned:
+				cloned++
+			case core.RestoreNoRemote:
+				noRemote++
+			case core.RestoreFailed:
+				failed++
+			}
+			if r.Err != nil {
+				fmt.Printf("  %-40s %s: %v\n", r.Target, r.Status, r.Err)
+			} else {
+				fmt.Printf("  %-40s %s\n", r.Target, r.Status)
+			}
+		}
+		fmt.Printf("%d cloned, %d failed, %d already present, %d skipped (no remote)\n", cloned, failed, present, noRemote)
+
+		if cloned > 0 {
+			if _, err := core.RefreshCache(false); err != nil {
+				fmt.Printf("Error refreshing cache: %v\n", err)
+			}
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var manifestDiffCmd = &cobra.Command{
+	Use:   "diff <file>",
+	Short: "Compare this machine's repositories against a manifest",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		other, err := core.ReadManifest(args[0])
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v\n", err)
+			os.Exit(1)
+		}
+		local, err := core.BuildManifest(context.Background())
+		if err != nil {
+			fmt.Printf("Error building manifest: %v\n", err)
+			os.Exit(1)
+		}
+
+		onlyLocal, onlyOther := core.DiffManifests(local, other)
+		for _, repo := range onlyLocal {
+			fmt.Printf("+ %s/%s\n", repo.Root, repo.Path)
+		}
+		for _, repo := range onlyOther {
+			fmt.Printf("- %s/%s\n", repo.Root, repo.Path)
+		}
+		fmt.Printf("%d only on this machine, %d only in %s\n", len(onlyLocal), len(onlyOther), args[0])
+	},
+}
+
+func init() {
+	manifestExportCmd.Flags().BoolVar(&manifestJSON, "json", false, "print JSON instead of YAML when writing to stdout")
+	manifestRestoreCmd.Flags().IntVarP(&manifestRestoreJobs, "jobs", "j", runtime.NumCPU(), "number of repositories to clone in parallel")
+
+	manifestCmd.AddCommand(manifestExportCmd, manifestRestoreCmd, manifestDiffCmd)
+	RootCmd.AddCommand(manifestCmd)
+}
@@ -2,21 +2,71 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
 	"github.com/spf13/cobra"
 )
 
+var (
+	refreshStrict  bool
+	refreshIfStale bool
+)
+
+// printScanReport prints per-root timing and every directory that could not be scanned
+func printScanReport(report *core.ScanReport) {
+	for _, root := range report.Roots {
+		if root.Err != nil {
+			fmt.Printf("  %-30s failed: %v\n", root.Root, root.Err)
+			continue
+		}
+		fmt.Printf("  %-30s %d repos in %s\n", root.Root, root.Repos, root.Duration.Round(time.Millisecond))
+		for _, path := range root.PermissionDenied {
+			fmt.Printf("    permission denied: %s\n", path)
+		}
+		for _, path := range root.SymlinkLoops {
+			fmt.Printf("    symlink loop: %s\n", path)
+		}
+		for _, msg := range root.Errors {
+			fmt.Printf("    error: %s\n", msg)
+		}
+	}
+}
+
 var refreshCmd = &cobra.Command{
 	Use:   "refresh",
 	Short: "Refresh the repository cache",
 	Long:  "Scans all configured paths and updates the repository cache",
+	// the cache is rebuilt anyway, so loading it first could only cost a second scan
+	Annotations: map[string]string{skipLoadAnnotation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := core.ResolvePaths(configPath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitConfig)
+		}
+		if err := core.LoadConfig(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(ExitConfig)
+		}
+
+		if refreshIfStale {
+			if err := core.RebuildCompletionIndex(); err != nil {
+				fmt.Printf("Error refreshing cache: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		fmt.Println("Refreshing repository cache...")
 
-		if err := core.RefreshCache(); err != nil {
+		report, err := core.RefreshCache(refreshStrict)
+		if report != nil {
+			printScanReport(report)
+		}
+		if err != nil {
 			fmt.Printf("Error refreshing cache: %v\n", err)
-			return
+			os.Exit(1)
 		}
 
 		fmt.Printf("Cache refreshed successfully. Found %d repositories.\n", len(core.ReposName))
@@ -24,5 +74,9 @@ var refreshCmd = &cobra.Command{
 }
 
 func init() {
+	refreshCmd.Flags().BoolVar(&refreshStrict, "strict", false, "fail without updating the cache if any root cannot be scanned completely")
+	// completion starts this in the background instead of rescanning inside a TAB press
+	refreshCmd.Flags().BoolVar(&refreshIfStale, "if-stale", false, "only rescan when the cache is stale, then rebuild the completion index")
+	_ = refreshCmd.Flags().MarkHidden("if-stale")
 	RootCmd.AddCommand(refreshCmd)
 }
@@ -1,33 +1,260 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
 
 	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
-var reposMap = core.ReposMap
-var reposName = core.ReposName
+// skipLoadAnnotation marks commands that must work without a loaded config or repo index
+const skipLoadAnnotation = "repo-switcher/skip-load"
+
+var (
+	configPath string
+	noHooks    bool
+)
+
+// needsLoad reports whether cmd requires the config and repo index before running
+func needsLoad(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "help":
+		// completion requests load lazily once their flags are parsed
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "completion" || c.Annotations[skipLoadAnnotation] == "true" {
+			return false
+		}
+	}
+	return true
+}
+
+// completeRepoNames completes the repo name argument from the prebuilt completion index, since completion skips
+// the pre-run load. It never scans: a stale index is served as is while a refresh runs in the background.
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	index, err := core.LoadCompletionIndex(configPath)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if index.Stale && core.ClaimBackgroundRefresh() {
+		refreshInBackground()
+	}
+
+	names, noSpace := index.Complete(toComplete)
+	directive := cobra.ShellCompDirectiveNoFileComp
+	if noSpace {
+		directive |= cobra.ShellCompDirectiveNoSpace
+	}
+	if index.KeepOrder {
+		directive |= cobra.ShellCompDirectiveKeepOrder
+	}
+	return names, directive
+}
+
+// refreshInBackground starts "refresh --if-stale" as a separate process that outlives the completion request
+func refreshInBackground() {
+	executable, err := os.Executable()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return
+	}
+	args := []string{"refresh", "--if-stale"}
+	if configPath != "" {
+		args = append(args, "--config", configPath)
+	}
+	refresh := exec.Command(executable, args...)
+	if err := refresh.Start(); err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return
+	}
+	_ = refresh.Process.Release()
+}
+
+// resolveOrClone resolves a repo name, cloning a listed forge repo into place when nothing local matches
+func resolveOrClone(repoName string) (string, error) {
+	path, err := core.ResolveRepo(repoName)
+	if !errors.Is(err, core.ErrRepoNotFound) {
+		return path, err
+	}
+
+	remote, remoteErr := core.FindRemoteRepo(repoName)
+	if remoteErr != nil {
+		var ambiguous *core.AmbiguousError
+		if errors.As(remoteErr, &ambiguous) {
+			return "", remoteErr
+		}
+		return "", err
+	}
+
+	fmt.Fprintf(os.Stderr, "Cloning %s from %s...\n", remote.FullName, remote.Forge)
+	return core.CloneRemoteRepo(context.Background(), *remote)
+}
+
+// exitWithResolveError reports a failed ResolveRepo, suggesting close names when nothing matched
+func exitWithResolveError(repoName string, err error) {
+	var ambiguous *core.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		exitWithLookupError(lookupError{
+			Error:      "ambiguous",
+			Name:       repoName,
+			Message:    fmt.Sprintf("Repository '%s' is ambiguous, use one of:", repoName),
+			Candidates: ambiguous.Candidates,
+		}, ExitAmbiguous)
+	}
+	if !errors.Is(err, core.ErrRepoNotFound) {
+		// e.g. cloning a forge repo failed
+		exitWithLookupError(lookupError{Error: "error", Name: repoName, Message: err.Error()}, 1)
+	}
+
+	exitWithLookupError(lookupError{
+		Error:       "not_found",
+		Name:        repoName,
+		Message:     fmt.Sprintf("Repository '%s' not found", repoName),
+		Suggestions: core.Suggest(repoName),
+	}, ExitNotFound)
+}
+
+// commandNames lists the names and aliases of the subcommands, which take precedence over repo names
+func commandNames() []string {
+	var names []string
+	for _, c := range RootCmd.Commands() {
+		names = append(names, c.Name())
+		names = append(names, c.Aliases...)
+	}
+	return names
+}
+
+// warnShadowedRepo points out that a subcommand was run where a repo of the same name may have been meant
+func warnShadowedRepo(cmd *cobra.Command) {
+	if cmd.Parent() != cmd.Root() {
+		return
+	}
+	if _, ok := core.ReposMap[cmd.CalledAs()]; ok {
+		log.Warn().Msgf("'%s' is also a repository; this ran the %s command, switch with `repo-switcher -- %s`", cmd.CalledAs(), cmd.Name(), cmd.CalledAs())
+	}
+}
 
 var RootCmd = &cobra.Command{
-	Use:          "repo-switcher [repo-name]",
-	Short:        "Switch to a git repository",
-	Args:         cobra.ExactArgs(1),
-	SilenceUsage: true,
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return reposName, cobra.ShellCompDirectiveNoFileComp
+	Use:           "repo-switcher [repo-name]",
+	Short:         "Switch to a git repository",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !needsLoad(cmd) {
+			return nil
+		}
+		err := core.Load(configPath)
+		if err != nil && !cmd.HasParent() {
+			// the lookup itself reports config errors like any other lookup failure
+			exitWithLookupError(lookupError{Error: "config", Message: err.Error()}, ExitCode(err))
+		}
+		if err == nil {
+			warnShadowedRepo(cmd)
+		}
+		return err
 	},
+	ValidArgsFunction: completeRepoNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		repoName := args[0]
 
-		if fullPath, exists := reposMap[repoName]; exists {
-			fmt.Println(fullPath)
-			os.Exit(0)
+		if n, ok := historyOffset(repoName); ok {
+			entry, err := core.HistoryBack(n)
+			if err != nil {
+				exitWithLookupError(lookupError{Error: "not_found", Name: repoName, Message: err.Error()}, ExitNotFound)
+			}
+			switchTo(entry.Path)
 		}
 
-		fmt.Printf("Repository '%s' not found\n", repoName)
-		os.Exit(1)
+		fullPath, err := resolveOrClone(repoName)
+		if err == nil {
+			switchTo(fullPath)
+		}
+
+		exitWithResolveError(repoName, err)
 	},
 }
+
+// historyArg matches `-` and `-N`, which go back through the switch history like `cd -`
+var historyArg = regexp.MustCompile(`^-[0-9]*$`)
+
+// historyOffset returns how many switches back a history argument points
+func historyOffset(arg string) (int, bool) {
+	if !historyArg.MatchString(arg) {
+		return 0, false
+	}
+	if arg == "-" {
+		return 1, true
+	}
+	n, err := strconv.Atoi(arg[1:])
+	return n, err == nil
+}
+
+// historyArgs inserts "--" before a trailing `-N` for the root lookup, which the flag parser would otherwise
+// read as a shorthand flag
+func historyArgs(args []string) []string {
+	if len(args) == 0 || slices.Contains(args, "--") || !historyArg.MatchString(args[len(args)-1]) {
+		return args
+	}
+	if cmd, _, err := RootCmd.Find(args); err != nil || cmd != RootCmd {
+		return args
+	}
+	return append(slices.Clone(args[:len(args)-1]), "--", args[len(args)-1])
+}
+
+// runPreHooks runs the pre hooks for a selected repo, exiting when one fails so the switch is cancelled
+func runPreHooks(path string) {
+	if noHooks {
+		return
+	}
+	if err := core.RunHooks(context.Background(), core.HookPre, path, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Switch cancelled: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runPostHooks runs the post hooks for a selected repo, only warning on failure
+func runPostHooks(path string) {
+	if noHooks {
+		return
+	}
+	if err := core.RunHooks(context.Background(), core.HookPost, path, os.Stderr); err != nil {
+		log.Warn().Err(err).Msg("hook failed")
+	}
+}
+
+// switchTo runs hooks, records the switch and prints the path for the shell to cd into
+func switchTo(path string) {
+	runPreHooks(path)
+	if err := core.RecordSwitch(path); err != nil {
+		log.Warn().Err(err).Msg("failed to record history")
+	}
+	fmt.Println(path)
+	runPostHooks(path)
+	os.Exit(0)
+}
+
+// Execute runs the command line; the history rewrite waits until every command file's init has registered its subcommands
+func Execute() error {
+	RootCmd.SetArgs(historyArgs(os.Args[1:]))
+	return RootCmd.Execute()
+}
+
+func init() {
+	RootCmd.Flags().BoolVar(&jsonErrors, "json", false, "print lookup errors as JSON on stderr")
+	RootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "skip hooks configured for the selected repository")
+	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "config file (default $"+core.EnvConfig+" or $XDG_CONFIG_HOME/repo-switcher/config.yaml)")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var sessionDetach bool
+
+var sessionCmd = &cobra.Command{
+	Use:               "session [repo-name]",
+	Short:             "Open a tmux session for a repository",
+	Long:              "Resolves a repository like the root command, then creates a tmux session named after it, rooted at its path and laid out from its group, and attaches or switches to it",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRepoNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoName := args[0]
+		repoPath, err := resolveOrClone(repoName)
+		if err != nil {
+			exitWithResolveError(repoName, err)
+		}
+
+		runPreHooks(repoPath)
+		session := core.TmuxSessionName(core.DisplayName(repoPath))
+		if _, err := core.EnsureSession(session, repoPath); err != nil {
+			fmt.Printf("Error creating tmux session: %v\n", err)
+			os.Exit(1)
+		}
+		if err := core.RecordSwitch(repoPath); err != nil {
+			log.Warn().Err(err).Msg("failed to record history")
+		}
+		runPostHooks(repoPath)
+
+		if sessionDetach {
+			fmt.Println(session)
+			return
+		}
+		if err := core.AttachSession(session); err != nil {
+			fmt.Printf("Error attaching to tmux session: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	sessionCmd.Flags().BoolVarP(&sessionDetach, "detach", "d", false, "create the session without attaching, printing its name")
+	sessionCmd.Flags().BoolVar(&jsonErrors, "json", false, "print lookup errors as JSON on stderr")
+	RootCmd.AddCommand(sessionCmd)
+}
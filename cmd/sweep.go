@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sweepGroup  string
+	sweepFilter string
+	sweepJobs   int
+)
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "List work that only exists on this machine",
+	Long:  "Reports uncommitted changes, untracked files, stashes, unpushed commits and branches without an upstream. Exits non-zero when anything is found.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repos, err := core.SelectRepos(sweepGroup, sweepFilter)
+		if err != nil {
+			fmt.Printf("Error selecting repositories: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := core.SweepRepos(context.Background(), repos, sweepJobs)
+
+		var dirty int
+		for _, r := range results {
+			if r.Err == nil && len(r.Findings) == 0 {
+				continue
+			}
+			dirty++
+
+			fmt.Printf("%s (%s)\n", r.Name, r.Path)
+			if r.Err != nil {
+				fmt.Printf("  error: %v\n", r.Err)
+			}
+			for _, f := range r.Findings {
+				fmt.Printf("  %-12s %s\n", f.Kind, f.Detail)
+			}
+		}
+
+		if dirty > 0 {
+			fmt.Printf("%d of %d repositories have local-only work\n", dirty, len(results))
+			os.Exit(1)
+		}
+		fmt.Printf("All %d repositories are clean and pushed\n", len(results))
+	},
+}
+
+func init() {
+	sweepCmd.Flags().StringVarP(&sweepGroup, "group", "g", "", "only sweep repositories of this config group")
+	sweepCmd.Flags().StringVarP(&sweepFilter, "filter", "f", "", "only sweep repositories whose name matches this glob")
+	sweepCmd.Flags().IntVarP(&sweepJobs, "jobs", "j", runtime.NumCPU(), "number of repositories to check in parallel")
+	RootCmd.AddCommand(sweepCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kahnwong/repo-switcher/internal/pkgs/core"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncGroup  string
+	syncFilter string
+	syncJobs   int
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Fast-forward every clean repository",
+	Long:  "Fetches all remotes and fast-forwards repositories that are clean and on a tracking branch. Dirty or diverged repositories are never touched.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repos, err := core.SelectRepos(syncGroup, syncFilter)
+		if err != nil {
+			fmt.Printf("Error selecting repositories: %v\n", err)
+			os.Exit(1)
+		}
+
+		results := core.SyncRepos(context.Background(), repos, syncJobs)
+
+		counts := make(map[core.SyncStatus]int)
+		for _, r := range results {
+			counts[r.Status]++
+			if r.Err != nil {
+				fmt.Printf("  %-30s %s: %v\n", r.Name, r.Status, r.Err)
+			} else {
+				fmt.Printf("  %-30s %s\n", r.Name, r.Status)
+			}
+		}
+		fmt.Printf("%d updated, %d up-to-date, %d skipped, %d diverged, %d failed\n",
+			counts[core.SyncUpdated],
+			counts[core.SyncUpToDate],
+			counts[core.SyncDirty]+counts[core.SyncNoUpstream],
+			counts[core.SyncDiverged],
+			counts[core.SyncFailed],
+		)
+
+		if counts[core.SyncFailed] > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	syncCmd.Flags().StringVarP(&syncGroup, "group", "g", "", "only sync repositories of this config group")
+	syncCmd.Flags().StringVarP(&syncFilter, "filter", "f", "", "only sync repositories whose name matches this glob")
+	syncCmd.Flags().IntVarP(&syncJobs, "jobs", "j", runtime.NumCPU(), "number of repositories to sync in parallel")
+	RootCmd.AddCommand(syncCmd)
+}
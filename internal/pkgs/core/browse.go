@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const ForgeBitbucket = "bitbucket"
+
+// webStyle picks the URL scheme for a remote host: a forge configured with that host decides,
+// otherwise the host name is recognised, falling back to GitHub's scheme
+func webStyle(host string) string {
+	if AppConfig != nil {
+		for _, forge := range AppConfig.Forges {
+			if u, err := url.Parse(forge.URL); err == nil && strings.EqualFold(u.Hostname(), host) {
+				return forge.Type
+			}
+		}
+	}
+
+	lower := strings.ToLower(host)
+	for _, style := range []string{ForgeGitLab, ForgeBitbucket, ForgeGitea} {
+		if strings.Contains(lower, style) {
+			return style
+		}
+	}
+	return ForgeGitHub
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a URL
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// webRef describes what a web URL points at in a repo
+type webRef struct {
+	// Ref is a branch name, or a commit when Commit is set
+	Ref    string
+	Commit bool
+	// IsDefault leaves the ref out of repo URLs, since the landing page already shows it
+	IsDefault bool
+	File      string
+	Line      int
+}
+
+// buildWebURL turns a remote URL into the web URL of the repo, or of a file at a ref, in the forge's scheme
+func buildWebURL(remote, style string, ref webRef) (string, error) {
+	host, path, ok := parseRemoteURL(remote)
+	if !ok || path == "" {
+		return "", fmt.Errorf("remote '%s' is not a web-hosted URL", remote)
+	}
+
+	scheme := "https"
+	if strings.HasPrefix(remote, "http://") {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/%s", scheme, host, path)
+
+	if ref.File == "" && (ref.IsDefault || ref.Ref == "") {
+		return base, nil
+	}
+
+	refPath := escapePath(ref.Ref)
+	var prefix, anchor string
+	switch style {
+	case ForgeGitLab:
+		prefix = "/-/tree/"
+		if ref.File != "" {
+			prefix = "/-/blob/"
+		}
+		anchor = "#L%d"
+	case ForgeBitbucket:
+		prefix = "/src/"
+		anchor = "#lines-%d"
+	case ForgeGitea:
+		prefix = "/src/branch/"
+		if ref.Commit {
+			prefix = "/src/commit/"
+		}
+		anchor = "#L%d"
+	default:
+		prefix = "/tree/"
+		if ref.File != "" {
+			prefix = "/blob/"
+		}
+		anchor = "#L%d"
+	}
+
+	webURL := base + prefix + refPath
+	if ref.File != "" {
+		webURL += "/" + escapePath(ref.File)
+		if ref.Line > 0 {
+			webURL += fmt.Sprintf(anchor, ref.Line)
+		}
+	}
+	return webURL, nil
+}
+
+// BrowseURL returns the web page of a repo's origin at its current branch, or of file (relative to the
+// repo root) and line when given
+func BrowseURL(ctx context.Context, repoPath, file string, line int) (string, error) {
+	remote, err := runGit(ctx, repoPath, "remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("no origin remote: %w", err)
+	}
+
+	ref := webRef{File: filepath.ToSlash(file), Line: line}
+	if branch, err := runGit(ctx, repoPath, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		ref.Ref = branch
+		// only origin knows its default branch; without it every branch gets its own URL
+		if head, err := runGit(ctx, repoPath, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+			ref.IsDefault = branch == strings.TrimPrefix(head, "origin/")
+		}
+	} else if commit, err := runGit(ctx, repoPath, "rev-parse", "HEAD"); err == nil {
+		ref.Ref, ref.Commit = commit, true
+	}
+
+	host, _, _ := parseRemoteURL(remote)
+	return buildWebURL(remote, webStyle(stripPort(host)), ref)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuildWebURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   string
+		style    string
+		ref      webRef
+		expected string
+	}{
+		{"github ssh default branch", "git@github.com:kahnwong/repo-switcher.git", ForgeGitHub, webRef{Ref: "main", IsDefault: true}, "https://github.com/kahnwong/repo-switcher"},
+		{"github branch", "https://github.com/kahnwong/repo-switcher.git", ForgeGitHub, webRef{Ref: "feat/x"}, "https://github.com/kahnwong/repo-switcher/tree/feat/x"},
+		{"github file and line", "ssh://git@github.com/kahnwong/repo-switcher", ForgeGitHub, webRef{Ref: "main", IsDefault: true, File: "cmd/root.go", Line: 42}, "https://github.com/kahnwong/repo-switcher/blob/main/cmd/root.go#L42"},
+		{"gitlab subgroup file", "git@gitlab.com:platform/tools/ci.git", ForgeGitLab, webRef{Ref: "main", File: "README.md", Line: 3}, "https://gitlab.com/platform/tools/ci/-/blob/main/README.md#L3"},
+		{"gitlab branch", "https://gitlab.com/platform/api", ForgeGitLab, webRef{Ref: "dev"}, "https://gitlab.com/platform/api/-/tree/dev"},
+		{"bitbucket file", "git@bitbucket.org:team/app.git", ForgeBitbucket, webRef{Ref: "main", File: "src/app.go", Line: 7}, "https://bitbucket.org/team/app/src/main/src/app.go#lines-7"},
+		{"gitea branch", "https://gitea.example.com/alice/tool.git", ForgeGitea, webRef{Ref: "dev"}, "https://gitea.example.com/alice/tool/src/branch/dev"},
+		{"gitea commit file", "https://gitea.example.com/alice/tool.git", ForgeGitea, webRef{Ref: "abc123", Commit: true, File: "a b.txt"}, "https://gitea.example.com/alice/tool/src/commit/abc123/a%20b.txt"},
+		{"plain http kept", "http://git.local/team/app.git", ForgeGitHub, webRef{}, "http://git.local/team/app"},
+		{"https port kept", "https://git.example.com:3000/team/app.git", ForgeGitea, webRef{Ref: "dev"}, "https://git.example.com:3000/team/app/src/branch/dev"},
+		{"ssh port dropped", "ssh://git@git.example.com:2222/team/app.git", ForgeGitHub, webRef{}, "https://git.example.com/team/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := buildWebURL(tt.remote, tt.style, tt.ref)
+			if err != nil {
+				t.Fatalf("buildWebURL() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("buildWebURL() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := buildWebURL("/srv/git/app.git", ForgeGitHub, webRef{}); err == nil {
+		t.Error("buildWebURL() for a local remote should fail")
+	}
+}
+
+func TestWebStyle(t *testing.T) {
+	originalAppConfig := AppConfig
+	t.Cleanup(func() { AppConfig = originalAppConfig })
+	AppConfig = &Config{Forges: []ForgeConfig{{Type: ForgeGitea, URL: "https://code.example.com/api/v1", Owner: "me"}}}
+
+	tests := map[string]string{
+		"github.com":       ForgeGitHub,
+		"gitlab.corp.net":  ForgeGitLab,
+		"bitbucket.org":    ForgeBitbucket,
+		"gitea.com":        ForgeGitea,
+		"code.example.com": ForgeGitea,
+		"git.example.com":  ForgeGitHub,
+	}
+	for host, expected := range tests {
+		if result := webStyle(host); result != expected {
+			t.Errorf("webStyle(%s) = %s, want %s", host, result, expected)
+		}
+	}
+}
+
+func TestBrowseURL(t *testing.T) {
+	_, clone := newRemoteAndClone(t)
+	gitCmd(t, clone, "remote", "set-url", "origin", "git@github.com:kahnwong/repo-switcher.git")
+	ctx := context.Background()
+
+	// origin/HEAD points at main, so main gets the plain repo URL
+	if url, err := BrowseURL(ctx, clone, "", 0); err != nil || url != "https://github.com/kahnwong/repo-switcher" {
+		t.Errorf("BrowseURL() on default branch = %s, %v", url, err)
+	}
+
+	gitCmd(t, clone, "checkout", "-q", "-b", "feature")
+	if url, err := BrowseURL(ctx, clone, "README.md", 1); err != nil || url != "https://github.com/kahnwong/repo-switcher/blob/feature/README.md#L1" {
+		t.Errorf("BrowseURL() on feature branch = %s, %v", url, err)
+	}
+
+	gitCmd(t, clone, "checkout", "-q", "--detach")
+	commit := gitCmd(t, clone, "rev-parse", "HEAD")
+	if url, err := BrowseURL(ctx, clone, "", 0); err != nil || url != "https://github.com/kahnwong/repo-switcher/tree/"+commit {
+		t.Errorf("BrowseURL() detached = %s, %v", url, err)
+	}
+
+	gitCmd(t, clone, "remote", "remove", "origin")
+	if _, err := BrowseURL(ctx, clone, "", 0); err == nil {
+		t.Error("BrowseURL() without origin should fail")
+	}
+}
@@ -5,14 +5,30 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+type Repo struct {
+	// Path is where the repo was found, possibly through a symlink
+	Path string `json:"path"`
+	// RealPath is the canonical location with symlinks resolved
+	RealPath string `json:"real_path"`
+	// Root is the resolved root directory the repo was found under
+	Root string `json:"root"`
+	// Meta is read from the repo's .repo-switcher.yaml, if it has one
+	Meta *RepoMeta `json:"meta,omitempty"`
+	// Activity is read from .git when scanning, for sorting by recency
+	Activity *RepoActivity `json:"activity,omitempty"`
+	// Branch is the branch checked out when the repo was scanned
+	Branch string `json:"branch,omitempty"`
+}
+
 type RepoCache struct {
-	Repos     []string  `json:"repos"`
+	Repos     []Repo    `json:"repos"`
 	Timestamp time.Time `json:"timestamp"`
 	PathsHash string    `json:"paths_hash"`
 }
@@ -47,7 +63,7 @@ func readCache() (*RepoCache, error) {
 }
 
 // writeCache writes the cache to disk
-func writeCache(repos []string, paths []string) error {
+func writeCache(repos []Repo, paths []string) error {
 	cache := RepoCache{
 		Repos:     repos,
 		Timestamp: time.Now(),
@@ -60,13 +76,22 @@ func writeCache(repos []string, paths []string) error {
 	}
 
 	// Ensure the directory exists
-	if err := os.MkdirAll(AppConfigBasePath, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(cacheFilePath), 0755); err != nil {
 		return err
 	}
 
 	return os.WriteFile(cacheFilePath, data, 0644)
 }
 
+// cacheKeys identifies the scan inputs a cache was built from
+func cacheKeys(roots []resolvedRoot, followSymlinks bool) []string {
+	keys := rootKeys(roots)
+	if followSymlinks {
+		keys = append(keys, "follow_symlinks")
+	}
+	return keys
+}
+
 // isCacheValid checks if the cache is still valid
 func isCacheValid(cache *RepoCache, paths []string) bool {
 	// Check if cache is too old
@@ -85,14 +110,19 @@ func isCacheValid(cache *RepoCache, paths []string) bool {
 	return true
 }
 
-// listGitReposWithCache returns git repos using cache when possible
-func listGitReposWithCache(paths []string, forceRefresh bool) ([]string, error) {
+// listGitReposWithCache returns git repos using cache when possible.
+// The scan report is nil when the cache was used. In strict mode an incomplete scan is an error and is not cached.
+func listGitReposWithCache(paths []string, followSymlinks bool, forceRefresh bool, strict bool) ([]Repo, *ScanReport, error) {
+	// Validate against the expanded roots so newly matching globs trigger a rescan
+	roots := resolveRoots(paths)
+	keys := cacheKeys(roots, followSymlinks)
+
 	// If force refresh is requested, skip cache
 	if !forceRefresh {
 		cache, err := readCache()
-		if err == nil && isCacheValid(cache, paths) {
+		if err == nil && isCacheValid(cache, keys) {
 			log.Debug().Msg("using cached repository list")
-			return cache.Repos, nil
+			return cache.Repos, nil, nil
 		}
 		if err != nil {
 			log.Debug().Err(err).Msg("failed to read cache")
@@ -101,16 +131,19 @@ func listGitReposWithCache(paths []string, forceRefresh bool) ([]string, error)
 
 	// Cache miss or invalid - scan directories
 	log.Debug().Msg("scanning directories for git repositories")
-	repos, err := listGitRepos(paths)
-	if err != nil {
-		return nil, err
+	repos, report := scanRoots(roots, followSymlinks)
+	if err := report.Err(); err != nil {
+		if strict {
+			return nil, report, err
+		}
+		log.Debug().Err(err).Msg("incomplete scan")
 	}
 
 	// Write to cache
-	if err := writeCache(repos, paths); err != nil {
+	if err := writeCache(repos, keys); err != nil {
 		log.Warn().Err(err).Msg("failed to write cache")
 		// Don't fail if cache write fails, just continue
 	}
 
-	return repos, nil
+	return repos, report, nil
 }
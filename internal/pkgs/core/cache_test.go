@@ -78,9 +78,9 @@ func TestWriteAndReadCache(t *testing.T) {
 	AppConfigBasePath = tempDir
 	cacheFilePath = filepath.Join(tempDir, cacheFileName)
 
-	repos := []string{
-		"/home/user/projects/repo1",
-		"/home/user/projects/repo2",
+	repos := []Repo{
+		{Path: "/home/user/projects/repo1", RealPath: "/home/user/projects/repo1"},
+		{Path: "/home/user/link/repo2", RealPath: "/home/user/projects/repo2"},
 	}
 	paths := []string{"/home/user/projects"}
 
@@ -108,7 +108,7 @@ func TestWriteAndReadCache(t *testing.T) {
 
 	for i, repo := range repos {
 		if cache.Repos[i] != repo {
-			t.Errorf("readCache() repo[%d] = %s, want %s", i, cache.Repos[i], repo)
+			t.Errorf("readCache() repo[%d] = %+v, want %+v", i, cache.Repos[i], repo)
 		}
 	}
 
@@ -169,7 +169,7 @@ func TestIsCacheValid(t *testing.T) {
 		{
 			name: "valid cache",
 			cache: &RepoCache{
-				Repos:     []string{"/home/user/projects/repo1"},
+				Repos:     []Repo{{Path: "/home/user/projects/repo1"}},
 				Timestamp: time.Now(),
 				PathsHash: pathsHash,
 			},
@@ -179,7 +179,7 @@ func TestIsCacheValid(t *testing.T) {
 		{
 			name: "expired cache",
 			cache: &RepoCache{
-				Repos:     []string{"/home/user/projects/repo1"},
+				Repos:     []Repo{{Path: "/home/user/projects/repo1"}},
 				Timestamp: time.Now().Add(-25 * time.Hour), // older than cacheTTL
 				PathsHash: pathsHash,
 			},
@@ -189,7 +189,7 @@ func TestIsCacheValid(t *testing.T) {
 		{
 			name: "paths changed",
 			cache: &RepoCache{
-				Repos:     []string{"/home/user/projects/repo1"},
+				Repos:     []Repo{{Path: "/home/user/projects/repo1"}},
 				Timestamp: time.Now(),
 				PathsHash: hashPaths([]string{"/different/path"}),
 			},
@@ -199,7 +199,7 @@ func TestIsCacheValid(t *testing.T) {
 		{
 			name: "both expired and paths changed",
 			cache: &RepoCache{
-				Repos:     []string{"/home/user/projects/repo1"},
+				Repos:     []Repo{{Path: "/home/user/projects/repo1"}},
 				Timestamp: time.Now().Add(-25 * time.Hour),
 				PathsHash: hashPaths([]string{"/different/path"}),
 			},
@@ -233,7 +233,7 @@ func TestWriteCacheCreatesDirectory(t *testing.T) {
 	AppConfigBasePath = nestedDir
 	cacheFilePath = filepath.Join(nestedDir, cacheFileName)
 
-	repos := []string{"/home/user/projects/repo1"}
+	repos := []Repo{{Path: "/home/user/projects/repo1", RealPath: "/home/user/projects/repo1"}}
 	paths := []string{"/home/user/projects"}
 
 	// Directory should not exist yet
@@ -266,7 +266,7 @@ func TestCacheJSONFormat(t *testing.T) {
 	tempDir := t.TempDir()
 	cacheFilePath = filepath.Join(tempDir, cacheFileName)
 
-	repos := []string{"/home/user/projects/repo1"}
+	repos := []Repo{{Path: "/home/user/projects/repo1", RealPath: "/home/user/projects/repo1"}}
 	paths := []string{"/home/user/projects"}
 
 	err := writeCache(repos, paths)
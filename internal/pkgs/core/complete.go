@@ -0,0 +1,264 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	completionIndexFileName = "completion-index"
+	completionIndexHeader   = "repo-switcher completion index v1"
+
+	refreshMarkerFileName = "refresh.pending"
+	// refreshBackoff spaces out background refreshes that did not finish, so repeated TAB presses don't pile up scans
+	refreshBackoff = time.Minute
+)
+
+var errCompletionIndexStale = errors.New("completion index is out of date")
+
+// CompletionIndex is what shell completion serves from, prebuilt so a TAB press neither
+// parses the config nor decodes the repo cache
+type CompletionIndex struct {
+	// Relative completes names one path segment at a time
+	Relative bool
+	// KeepOrder tells shells not to re-sort candidates
+	KeepOrder bool
+	// Names are the local repo names in sort order, Descriptions their completion descriptions
+	Names        []string
+	Descriptions []string
+	// Remotes are uncloned forge repos that are cloned on pick
+	Remotes []string
+	// Stale is set when the index is missing, was built from files that changed since,
+	// or lists repos past the cache TTL; it is still served while a rebuild runs in the background
+	Stale bool
+}
+
+// Complete returns the candidates for toComplete, with descriptions after a tab
+func (index *CompletionIndex) Complete(toComplete string) (candidates []string, noSpace bool) {
+	candidates, noSpace = completeNames(index.Names, index.Descriptions, index.Relative, index.KeepOrder, toComplete)
+	for _, name := range index.Remotes {
+		if strings.HasPrefix(name, toComplete) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates, noSpace
+}
+
+// completionIndexPath keeps the index next to the repo cache
+func completionIndexPath() string {
+	return filepath.Join(filepath.Dir(cacheFilePath), completionIndexFileName)
+}
+
+// completionInputs lists every file the index is derived from, so a change to any of them invalidates it
+func completionInputs() []string {
+	files := []string{AppConfigPath, cacheFilePath}
+	// origins name the file each value came from, which covers includes
+	for _, origin := range ConfigOrigins {
+		if strings.HasPrefix(origin, "env ") {
+			continue
+		}
+		file, _, _ := strings.Cut(origin, " (hosts.")
+		if !containsString(files, file) {
+			files = append(files, file)
+		}
+	}
+	if sortStrategy() == SortFrecency {
+		files = append(files, historyFilePath)
+	}
+	if AppConfig != nil && len(AppConfig.Forges) > 0 {
+		files = append(files, forgeCachePath())
+	}
+	return files
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fileStamp identifies the current version of a file by modification time and size, "-" when missing
+func fileStamp(path string) (stamp string, modified time.Time) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "-", time.Time{}
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10) + " " + strconv.FormatInt(info.Size(), 10), info.ModTime()
+}
+
+// buildCompletionIndex builds the index from the loaded config and repos
+func buildCompletionIndex() *CompletionIndex {
+	byPath := make(map[string]*Repo, len(indexedRepos))
+	for i := range indexedRepos {
+		byPath[indexedRepos[i].Path] = &indexedRepos[i]
+	}
+
+	index := &CompletionIndex{
+		Relative:     naming() == NamingRelative,
+		KeepOrder:    KeepsOrder(),
+		Names:        make([]string, 0, len(ReposName)),
+		Descriptions: make([]string, 0, len(ReposName)),
+		Remotes:      RemoteCandidates(),
+	}
+	for _, name := range ReposName {
+		// shells split candidates on tabs and newlines, such names cannot be completed anyway
+		if strings.ContainsAny(name, "\t\n") {
+			continue
+		}
+		var description string
+		if repo := byPath[ReposMap[name]]; repo != nil {
+			description = describeRepo(repo, name)
+		}
+		index.Names = append(index.Names, name)
+		index.Descriptions = append(index.Descriptions, description)
+	}
+	return index
+}
+
+// writeCompletionIndex saves the index for the loaded config and repos, stamped with the files it came from.
+// The format is line based so reading it costs little more than splitting the file:
+// "c" names the config file, "f" lines stamp inputs, "e" holds $REPO_SWITCHER_PATHS, "o" the options, "n" names and "r" remotes.
+func writeCompletionIndex() error {
+	index := buildCompletionIndex()
+
+	var b strings.Builder
+	b.WriteString(completionIndexHeader + "\n")
+	fmt.Fprintf(&b, "c\t%s\n", AppConfigPath)
+	for _, file := range completionInputs() {
+		stamp, _ := fileStamp(file)
+		fmt.Fprintf(&b, "f\t%s\t%s\n", file, stamp)
+	}
+	fmt.Fprintf(&b, "e\t%s\n", os.Getenv(EnvPaths))
+	fmt.Fprintf(&b, "o\t%t\t%t\n", index.Relative, index.KeepOrder)
+	for i, name := range index.Names {
+		fmt.Fprintf(&b, "n\t%s\t%s\n", name, index.Descriptions[i])
+	}
+	for _, name := range index.Remotes {
+		fmt.Fprintf(&b, "r\t%s\n", name)
+	}
+
+	path := completionIndexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), completionIndexFileName+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// saveCompletionIndex refreshes the index after the repos were rescanned or the index went stale,
+// clearing the claim of the background refresh that asked for it
+func saveCompletionIndex() {
+	if err := writeCompletionIndex(); err != nil {
+		log.Debug().Err(err).Msg("failed to write completion index")
+		return
+	}
+	_ = os.Remove(filepath.Join(filepath.Dir(cacheFilePath), refreshMarkerFileName))
+}
+
+// RebuildCompletionIndex brings the completion index up to date for the loaded config,
+// rescanning only when the cache itself is stale
+func RebuildCompletionIndex() error {
+	if err := LoadRepos(); err != nil {
+		return err
+	}
+	saveCompletionIndex()
+	return nil
+}
+
+// readCompletionIndex reads the index, flagging it stale when any of the files it was built from changed.
+// An index built for another config file or other $REPO_SWITCHER_PATHS lists the wrong repos and fails.
+func readCompletionIndex() (*CompletionIndex, error) {
+	data, err := os.ReadFile(completionIndexPath())
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	if lines[0] != completionIndexHeader {
+		return nil, fmt.Errorf("unknown completion index format")
+	}
+
+	index := &CompletionIndex{}
+	for _, line := range lines[1:] {
+		kind, value, _ := strings.Cut(line, "\t")
+		switch kind {
+		case "n":
+			name, description, _ := strings.Cut(value, "\t")
+			index.Names = append(index.Names, name)
+			index.Descriptions = append(index.Descriptions, description)
+		case "r":
+			index.Remotes = append(index.Remotes, value)
+		case "o":
+			relative, keepOrder, _ := strings.Cut(value, "\t")
+			index.Relative, index.KeepOrder = relative == "true", keepOrder == "true"
+		case "c":
+			if value != AppConfigPath {
+				return nil, errCompletionIndexStale
+			}
+		case "e":
+			if value != os.Getenv(EnvPaths) {
+				return nil, errCompletionIndexStale
+			}
+		case "f":
+			file, want, _ := strings.Cut(value, "\t")
+			stamp, modified := fileStamp(file)
+			if stamp != want || (file == cacheFilePath && time.Since(modified) > cacheTTL) {
+				index.Stale = true
+			}
+		}
+	}
+	return index, nil
+}
+
+// LoadCompletionIndex returns the completion index for the current config. It only ever reads the
+// index file, never the config or the repo cache, so a TAB press costs the same however the index
+// got out of date; stale or missing indexes are rebuilt by a background refresh.
+func LoadCompletionIndex(configFlag string) (*CompletionIndex, error) {
+	if err := ResolvePaths(configFlag); err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+	index, err := readCompletionIndex()
+	if err != nil {
+		log.Debug().Err(err).Msg("no usable completion index")
+		return &CompletionIndex{Stale: true}, nil
+	}
+	return index, nil
+}
+
+// ClaimBackgroundRefresh reports whether the caller should start a background refresh,
+// letting at most one through per refreshBackoff
+func ClaimBackgroundRefresh() bool {
+	marker := filepath.Join(filepath.Dir(cacheFilePath), refreshMarkerFileName)
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < refreshBackoff {
+		return false
+	}
+	if err := os.MkdirAll(filepath.Dir(marker), 0755); err != nil {
+		return false
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		return false
+	}
+	now := time.Now()
+	return os.Chtimes(marker, now, now) == nil
+}
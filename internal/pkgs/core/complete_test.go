@@ -0,0 +1,238 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setupCompletion points config, cache and state at a temp dir with a config naming repos relative
+// to one root, and caches n repos under it, returning the config path
+func setupCompletion(tb testing.TB, n int) string {
+	tb.Helper()
+
+	config, origins, reposMap, reposName, repos, aliases := AppConfig, ConfigOrigins, ReposMap, ReposName, indexedRepos, repoAliases
+	paths := []string{AppConfigPath, AppConfigBasePath, AppCacheBasePath, AppStateBasePath, cacheFilePath, historyFilePath}
+	tb.Cleanup(func() {
+		AppConfig, ConfigOrigins, ReposMap, ReposName, indexedRepos, repoAliases = config, origins, reposMap, reposName, repos, aliases
+		AppConfigPath, AppConfigBasePath, AppCacheBasePath, AppStateBasePath, cacheFilePath, historyFilePath = paths[0], paths[1], paths[2], paths[3], paths[4], paths[5]
+	})
+
+	dir := tb.TempDir()
+	tb.Setenv("HOME", dir)
+	tb.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	tb.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	tb.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+	tb.Setenv(EnvConfig, "")
+	tb.Setenv(EnvPaths, "")
+
+	root := filepath.Join(dir, "src")
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("paths:\n  - "+root+"\nnaming: relative\ncompletion_fields: [branch]\n"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	if err := ResolvePaths(configPath); err != nil {
+		tb.Fatal(err)
+	}
+
+	cached := make([]Repo, n)
+	for i := range cached {
+		path := filepath.Join(root, fmt.Sprintf("org%d", i%100), fmt.Sprintf("repo%d", i))
+		cached[i] = Repo{Path: path, RealPath: path, Root: root, Branch: "main"}
+	}
+	if err := writeCache(cached, cacheKeys(resolveRoots([]string{root}), false)); err != nil {
+		tb.Fatal(err)
+	}
+	return configPath
+}
+
+// rebuildCompletionIndex does what the background refresh does for the config at configPath
+func rebuildCompletionIndex(tb testing.TB) {
+	tb.Helper()
+	if err := LoadConfig(); err != nil {
+		tb.Fatalf("LoadConfig() error = %v", err)
+	}
+	if err := RebuildCompletionIndex(); err != nil {
+		tb.Fatalf("RebuildCompletionIndex() error = %v", err)
+	}
+}
+
+func TestLoadCompletionIndex(t *testing.T) {
+	configPath := setupCompletion(t, 3)
+
+	// without an index nothing is served, and the TAB press neither scans nor rebuilds
+	index, err := LoadCompletionIndex(configPath)
+	if err != nil {
+		t.Fatalf("LoadCompletionIndex() error = %v", err)
+	}
+	if !index.Stale || len(index.Names) != 0 {
+		t.Errorf("LoadCompletionIndex() without index = %+v, want empty stale index", index)
+	}
+	if _, err := os.Stat(completionIndexPath()); !os.IsNotExist(err) {
+		t.Errorf("LoadCompletionIndex() wrote the index: %v", err)
+	}
+
+	rebuildCompletionIndex(t)
+	index, err = LoadCompletionIndex(configPath)
+	if err != nil {
+		t.Fatalf("LoadCompletionIndex() error = %v", err)
+	}
+	if index.Stale || !index.Relative || index.KeepOrder {
+		t.Errorf("LoadCompletionIndex() = %+v, want fresh relative index", index)
+	}
+	expected := []string{"org0/repo0", "org1/repo1", "org2/repo2"}
+	if !reflect.DeepEqual(index.Names, expected) {
+		t.Errorf("Names = %v, want %v", index.Names, expected)
+	}
+	if !reflect.DeepEqual(index, buildCompletionIndex()) {
+		t.Errorf("LoadCompletionIndex() = %+v, want the saved index %+v", index, buildCompletionIndex())
+	}
+
+	if candidates, noSpace := index.Complete("org1"); !reflect.DeepEqual(candidates, []string{"org1/"}) || !noSpace {
+		t.Errorf("Complete(org1) = %v, %v", candidates, noSpace)
+	}
+	if candidates, _ := index.Complete("org1/"); !reflect.DeepEqual(candidates, []string{"org1/repo1\tmain"}) {
+		t.Errorf("Complete(org1/) = %v, want name with description", candidates)
+	}
+}
+
+func TestCompletionIndexInvalidation(t *testing.T) {
+	configPath := setupCompletion(t, 3)
+	rebuildCompletionIndex(t)
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, append(data, "sort: commit\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old names are still served, flagged for a rebuild
+	index, err := LoadCompletionIndex(configPath)
+	if err != nil {
+		t.Fatalf("LoadCompletionIndex() error = %v", err)
+	}
+	if !index.Stale || len(index.Names) != 3 || index.KeepOrder {
+		t.Errorf("LoadCompletionIndex() after config change = %+v, want the old index flagged stale", index)
+	}
+
+	// rebuilding picks up the new config
+	rebuildCompletionIndex(t)
+	index, err = LoadCompletionIndex(configPath)
+	if err != nil {
+		t.Fatalf("LoadCompletionIndex() error = %v", err)
+	}
+	if index.Stale || !index.KeepOrder {
+		t.Errorf("rebuilt index = %+v, want fresh with the commit sort order", index)
+	}
+
+	// an index for other paths lists the wrong repos
+	t.Setenv(EnvPaths, "/elsewhere")
+	if _, err := readCompletionIndex(); !errors.Is(err, errCompletionIndexStale) {
+		t.Errorf("readCompletionIndex() after %s change error = %v, want stale", EnvPaths, err)
+	}
+}
+
+func TestCompletionIndexStale(t *testing.T) {
+	setupCompletion(t, 3)
+	rebuildCompletionIndex(t)
+
+	// an expired cache is still served, flagged for a background refresh
+	old := time.Now().Add(-2 * cacheTTL)
+	if err := os.Chtimes(cacheFilePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCompletionIndex(); err != nil {
+		t.Fatal(err)
+	}
+	index, err := readCompletionIndex()
+	if err != nil {
+		t.Fatalf("readCompletionIndex() error = %v", err)
+	}
+	if !index.Stale || len(index.Names) != 3 {
+		t.Errorf("readCompletionIndex() = %+v, want stale index with names", index)
+	}
+}
+
+func TestClaimBackgroundRefresh(t *testing.T) {
+	setupCompletion(t, 0)
+
+	if !ClaimBackgroundRefresh() {
+		t.Fatal("first ClaimBackgroundRefresh() = false")
+	}
+	if ClaimBackgroundRefresh() {
+		t.Error("second ClaimBackgroundRefresh() within the backoff = true")
+	}
+
+	marker := filepath.Join(filepath.Dir(cacheFilePath), refreshMarkerFileName)
+	old := time.Now().Add(-2 * refreshBackoff)
+	if err := os.Chtimes(marker, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if !ClaimBackgroundRefresh() {
+		t.Error("ClaimBackgroundRefresh() after the backoff = false")
+	}
+
+	// a finished rebuild releases the claim
+	rebuildCompletionIndex(t)
+	if !ClaimBackgroundRefresh() {
+		t.Error("ClaimBackgroundRefresh() after a rebuild = false")
+	}
+}
+
+// BenchmarkLoadCompletionIndex measures what a TAB press costs with 10k repos indexed
+func BenchmarkLoadCompletionIndex(b *testing.B) {
+	configPath := setupCompletion(b, 10000)
+	rebuildCompletionIndex(b)
+
+	for b.Loop() {
+		index, err := LoadCompletionIndex(configPath)
+		if err != nil {
+			b.Fatal(err)
+		}
+		index.Complete("org4")
+	}
+}
+
+// BenchmarkLoadCompletionIndexStale measures a TAB press after the config changed, which costs the same
+func BenchmarkLoadCompletionIndexStale(b *testing.B) {
+	configPath := setupCompletion(b, 10000)
+	rebuildCompletionIndex(b)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(configPath, later, later); err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		index, err := LoadCompletionIndex(configPath)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !index.Stale {
+			b.Fatal("index not flagged stale")
+		}
+		index.Complete("org4")
+	}
+}
+
+// BenchmarkRebuildCompletionIndex measures the background rebuild from a fresh cache
+func BenchmarkRebuildCompletionIndex(b *testing.B) {
+	setupCompletion(b, 10000)
+	if err := LoadConfig(); err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		if err := RebuildCompletionIndex(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,61 +14,230 @@ import (
 )
 
 type Config struct {
-	Paths []string `yaml:"paths"`
+	Paths  []string            `yaml:"paths"`
+	Groups map[string][]string `yaml:"groups,omitempty"`
+	// FollowSymlinks scans into symlinked directories, skipping loops
+	FollowSymlinks bool `yaml:"follow_symlinks,omitempty"`
+	// Naming selects how repos are named: basename (default) or relative to their root
+	Naming string `yaml:"naming,omitempty"`
+	// Sort orders completion and listings: alphabetical (default), frecency, commit or mtime
+	Sort string `yaml:"sort,omitempty"`
+	// CompletionFields describe repos in shell completion: path (default), branch, group, tags or description
+	CompletionFields []string `yaml:"completion_fields,omitempty"`
+	// IgnoreCase matches names case-insensitively, e.g. MyService resolves myservice
+	IgnoreCase bool `yaml:"ignore_case,omitempty"`
+	// IgnoreSeparators matches names ignoring "-", "_", "." and spaces, e.g. foo_bar resolves foo-bar
+	IgnoreSeparators bool `yaml:"ignore_separators,omitempty"`
+	// Normalize selects the Unicode form names are compared in: nfc (default), nfd or none
+	Normalize string `yaml:"normalize,omitempty"`
+
+	// Hooks run shell commands when a repo is selected
+	Hooks []Hook `yaml:"hooks,omitempty"`
+	// Forges list repositories on GitHub, GitLab or Gitea that can be cloned on pick
+	Forges []ForgeConfig `yaml:"forges,omitempty"`
+	// Layouts sets up tmux windows for sessions of repos in a group, keyed by group name
+	Layouts map[string][]TmuxWindow `yaml:"layouts,omitempty"`
+
+	// Hosts overlays config per machine, keyed by hostname or glob
+	Hosts map[string]*Config `yaml:"hosts,omitempty"`
+	// Include merges extra files, relative to the including file
+	Include []string `yaml:"include,omitempty"`
+}
+
+const (
+	appName         = "repo-switcher"
+	configFileName  = "config.yaml"
+	legacyConfigDir = "~/.config/repo-switcher"
+
+	EnvConfig = "REPO_SWITCHER_CONFIG"
+	EnvPaths  = "REPO_SWITCHER_PATHS"
+)
+
+// ConfigError marks a failure to locate or read the config, as opposed to a failure to scan
+type ConfigError struct {
+	Err error
 }
 
+func (e *ConfigError) Error() string { return e.Err.Error() }
+func (e *ConfigError) Unwrap() error { return e.Err }
+
 var AppConfigBasePath string
+var AppConfigPath string
+var AppCacheBasePath string
+var AppStateBasePath string
 var AppConfig *Config
 
 var ReposMap map[string]string
+
+// indexedRepos holds every repo behind the name index, including ones whose name is shadowed
+var indexedRepos []Repo
+
+// repoAliases maps declared aliases to display names
+var repoAliases map[string]string
 var ReposName []string
 
 func init() {
 	// Set log level
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+}
+
+// xdgDir returns $envVar/repo-switcher, falling back to fallback under the home directory
+func xdgDir(envVar, fallback string) (string, error) {
+	if dir := os.Getenv(envVar); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	return cliBase.ExpandHome(fallback)
+}
+
+// resolveConfigPath picks the config file: --config, then $REPO_SWITCHER_CONFIG, then the XDG config directory
+func resolveConfigPath(configFlag string) (string, error) {
+	for _, path := range []string{configFlag, os.Getenv(EnvConfig)} {
+		if path != "" {
+			return cliBase.ExpandHome(path)
+		}
+	}
 
-	// Initialize config path
+	dir, err := xdgDir("XDG_CONFIG_HOME", "~/.config/"+appName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// migrateCache moves a cache file left next to the config by older versions into the cache directory
+func migrateCache(legacyPath, newPath string) error {
+	if legacyPath == newPath {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(legacyPath); err != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyPath, newPath); err == nil {
+		log.Debug().Str("from", legacyPath).Str("to", newPath).Msg("migrated cache")
+		return nil
+	}
+
+	// rename fails across filesystems, fall back to copying
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(newPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Remove(legacyPath)
+}
+
+// ResolvePaths sets the config, cache and state locations, migrating the cache from its legacy location
+func ResolvePaths(configFlag string) error {
 	var err error
-	AppConfigBasePath, err = cliBase.ExpandHome("~/.config/repo-switcher")
+	AppConfigPath, err = resolveConfigPath(configFlag)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to expand config path")
+		return fmt.Errorf("failed to resolve config path: %w", err)
 	}
+	AppConfigBasePath = filepath.Dir(AppConfigPath)
 
-	// Initialize cache file path
-	cacheFilePath = filepath.Join(AppConfigBasePath, cacheFileName)
+	AppCacheBasePath, err = xdgDir("XDG_CACHE_HOME", "~/.cache/"+appName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve cache path: %w", err)
+	}
+	cacheFilePath = filepath.Join(AppCacheBasePath, cacheFileName)
 
-	// Read config file
-	AppConfig, err = cliBase.ReadYaml[Config](fmt.Sprintf("%s/config.yaml", AppConfigBasePath))
+	AppStateBasePath, err = xdgDir("XDG_STATE_HOME", "~/.local/state/"+appName)
 	if err != nil {
-		if isTestMode() {
-			log.Warn().Err(err).Msg("failed to read config file")
-			return
+		return fmt.Errorf("failed to resolve state path: %w", err)
+	}
+	historyFilePath = filepath.Join(AppStateBasePath, historyFileName)
+
+	legacyDir, err := cliBase.ExpandHome(legacyConfigDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve legacy cache path: %w", err)
+	}
+	if err := migrateCache(filepath.Join(legacyDir, cacheFileName), cacheFilePath); err != nil {
+		log.Warn().Err(err).Msg("failed to migrate cache")
+	}
+
+	return nil
+}
+
+// LoadConfig reads the effective config, letting $REPO_SWITCHER_PATHS override the configured paths
+func LoadConfig() error {
+	envPaths := os.Getenv(EnvPaths)
+
+	var err error
+	AppConfig, ConfigOrigins, err = readConfig(AppConfigPath)
+	if err != nil {
+		// the config file is optional when paths come from the environment
+		if envPaths == "" || !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read config file: %w", err)
 		}
-		log.Fatal().Err(err).Msg("failed to read config file")
+		AppConfig, ConfigOrigins = &Config{}, make(map[string]string)
+	}
+
+	if envPaths != "" {
+		AppConfig.Paths = strings.Split(envPaths, string(os.PathListSeparator))
+		ConfigOrigins["paths"] = "env " + EnvPaths
+	}
+
+	if err := validateNaming(AppConfig.Naming); err != nil {
+		return err
+	}
+	if err := validateNormalize(AppConfig.Normalize); err != nil {
+		return err
+	}
+	if err := validateSort(AppConfig.Sort); err != nil {
+		return err
+	}
+	if err := validateCompletionFields(AppConfig.CompletionFields); err != nil {
+		return err
+	}
+	if err := validateHooks(AppConfig.Hooks, AppConfig.Groups); err != nil {
+		return err
 	}
+	return validateForges(AppConfig.Forges)
+}
 
+// LoadRepos fills ReposMap and ReposName from the cache, rescanning when it is stale
+func LoadRepos() error {
 	if AppConfig == nil {
-		if isTestMode() {
-			log.Warn().Msg("skipping repo initialization: config not loaded")
-			return
-		}
-		log.Fatal().Msg("config not loaded")
+		return fmt.Errorf("config not loaded")
 	}
 
-	repos, err := listGitReposWithCache(AppConfig.Paths, false)
+	repos, report, err := listGitReposWithCache(AppConfig.Paths, AppConfig.FollowSymlinks, false, false)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to list git repos")
+		return fmt.Errorf("failed to list git repos: %w", err)
 	}
 
-	ReposMap = createGitFolderMap(repos)
-	ReposName = getReposName(ReposMap)
+	setRepos(repos)
+	if report != nil {
+		saveCompletionIndex()
+	}
+	return nil
 }
 
-func createGitFolderMap(repos []string) map[string]string {
+// Load resolves paths, reads the config and loads the repo index
+func Load(configFlag string) error {
+	if err := ResolvePaths(configFlag); err != nil {
+		return &ConfigError{Err: err}
+	}
+	if err := LoadConfig(); err != nil {
+		return &ConfigError{Err: err}
+	}
+	return LoadRepos()
+}
+
+// createGitFolderMap names each repo by its declared name, falling back to its directory
+func createGitFolderMap(repos []Repo) map[string]string {
 	folderMap := make(map[string]string)
 	for _, repo := range repos {
-		folderName := filepath.Base(repo)
-		folderMap[folderName] = repo
+		folderMap[repoName(repo, NamingBasename)] = repo.Path
 	}
 	return folderMap
 }
@@ -79,23 +250,14 @@ func getReposName(reposMap map[string]string) []string {
 	return keys
 }
 
-func isTestMode() bool {
-	for _, arg := range os.Args {
-		if strings.HasPrefix(arg, "-test.") || strings.HasSuffix(arg, ".test") {
-			return true
-		}
-	}
-	return false
-}
-
 // entrypoint - for force refresh
-func RefreshCache() error {
-	repos, err := listGitReposWithCache(AppConfig.Paths, true)
+func RefreshCache(strict bool) (*ScanReport, error) {
+	repos, report, err := listGitReposWithCache(AppConfig.Paths, AppConfig.FollowSymlinks, true, strict)
 	if err != nil {
-		return err
+		return report, err
 	}
 
-	ReposMap = createGitFolderMap(repos)
-	ReposName = getReposName(ReposMap)
-	return nil
+	setRepos(repos)
+	saveCompletionIndex()
+	return report, nil
 }
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -8,18 +10,18 @@ import (
 func TestCreateGitFolderMap(t *testing.T) {
 	tests := []struct {
 		name     string
-		repos    []string
+		repos    []Repo
 		expected map[string]string
 	}{
 		{
 			name:     "empty repos",
-			repos:    []string{},
+			repos:    []Repo{},
 			expected: map[string]string{},
 		},
 		{
 			name: "single repo",
-			repos: []string{
-				"/home/user/projects/repo1",
+			repos: []Repo{
+				{Path: "/home/user/projects/repo1"},
 			},
 			expected: map[string]string{
 				"repo1": "/home/user/projects/repo1",
@@ -27,10 +29,10 @@ func TestCreateGitFolderMap(t *testing.T) {
 		},
 		{
 			name: "multiple repos",
-			repos: []string{
-				"/home/user/projects/repo1",
-				"/home/user/projects/repo2",
-				"/var/www/myapp",
+			repos: []Repo{
+				{Path: "/home/user/projects/repo1"},
+				{Path: "/home/user/projects/repo2"},
+				{Path: "/var/www/myapp"},
 			},
 			expected: map[string]string{
 				"repo1": "/home/user/projects/repo1",
@@ -40,14 +42,25 @@ func TestCreateGitFolderMap(t *testing.T) {
 		},
 		{
 			name: "repos with same basename",
-			repos: []string{
-				"/home/user/projects/repo1",
-				"/home/user/work/repo1",
+			repos: []Repo{
+				{Path: "/home/user/projects/repo1"},
+				{Path: "/home/user/work/repo1"},
 			},
 			expected: map[string]string{
 				"repo1": "/home/user/work/repo1", // last one wins
 			},
 		},
+		{
+			name: "declared name preferred",
+			repos: []Repo{
+				{Path: "/home/user/projects/repo1", Meta: &RepoMeta{Name: "api"}},
+				{Path: "/home/user/projects/repo2", Meta: &RepoMeta{Description: "no name declared"}},
+			},
+			expected: map[string]string{
+				"api":   "/home/user/projects/repo1",
+				"repo2": "/home/user/projects/repo2",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -112,3 +125,143 @@ func TestGetReposName(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveConfigPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("failed to get home directory: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		configFlag string
+		envConfig  string
+		xdgConfig  string
+		expected   string
+	}{
+		{
+			name:     "default",
+			expected: filepath.Join(home, ".config", "repo-switcher", "config.yaml"),
+		},
+		{
+			name:      "xdg config home",
+			xdgConfig: "/xdg",
+			expected:  "/xdg/repo-switcher/config.yaml",
+		},
+		{
+			name:      "env overrides xdg",
+			envConfig: "/env/config.yaml",
+			xdgConfig: "/xdg",
+			expected:  "/env/config.yaml",
+		},
+		{
+			name:       "flag overrides env",
+			configFlag: "~/flag.yaml",
+			envConfig:  "/env/config.yaml",
+			expected:   filepath.Join(home, "flag.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvConfig, tt.envConfig)
+			t.Setenv("XDG_CONFIG_HOME", tt.xdgConfig)
+
+			result, err := resolveConfigPath(tt.configFlag)
+			if err != nil {
+				t.Fatalf("resolveConfigPath() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("resolveConfigPath() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMigrateCache(t *testing.T) {
+	tempDir := t.TempDir()
+	legacyPath := filepath.Join(tempDir, "config", cacheFileName)
+	newPath := filepath.Join(tempDir, "cache", "repo-switcher", cacheFileName)
+
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(legacyPath, []byte(`{"repos":[]}`), 0644); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+
+	if err := migrateCache(legacyPath, newPath); err != nil {
+		t.Fatalf("migrateCache() error = %v", err)
+	}
+
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Error("migrateCache() did not remove legacy cache")
+	}
+	data, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatalf("migrateCache() did not create new cache: %v", err)
+	}
+	if string(data) != `{"repos":[]}` {
+		t.Errorf("migrateCache() content = %s, want legacy content", data)
+	}
+
+	// an existing cache is never overwritten
+	if err := os.WriteFile(legacyPath, []byte("stale"), 0644); err != nil {
+		t.Fatalf("failed to write legacy cache: %v", err)
+	}
+	if err := migrateCache(legacyPath, newPath); err != nil {
+		t.Fatalf("migrateCache() error = %v", err)
+	}
+	if data, _ := os.ReadFile(newPath); string(data) == "stale" {
+		t.Error("migrateCache() overwrote existing cache")
+	}
+}
+
+func TestLoadConfigEnvPaths(t *testing.T) {
+	originalAppConfig := AppConfig
+	originalAppConfigPath := AppConfigPath
+	originalOrigins := ConfigOrigins
+	defer func() {
+		AppConfig = originalAppConfig
+		AppConfigPath = originalAppConfigPath
+		ConfigOrigins = originalOrigins
+	}()
+
+	tempDir := t.TempDir()
+
+	t.Run("missing config without env paths", func(t *testing.T) {
+		t.Setenv(EnvPaths, "")
+		AppConfigPath = filepath.Join(tempDir, "missing.yaml")
+		if err := LoadConfig(); err == nil {
+			t.Error("LoadConfig() expected error for missing config, got nil")
+		}
+	})
+
+	t.Run("missing config with env paths", func(t *testing.T) {
+		t.Setenv(EnvPaths, "/a"+string(os.PathListSeparator)+"/b")
+		AppConfigPath = filepath.Join(tempDir, "missing.yaml")
+		if err := LoadConfig(); err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if !reflect.DeepEqual(AppConfig.Paths, []string{"/a", "/b"}) {
+			t.Errorf("LoadConfig() paths = %v, want [/a /b]", AppConfig.Paths)
+		}
+	})
+
+	t.Run("env paths override config", func(t *testing.T) {
+		t.Setenv(EnvPaths, "/env")
+		AppConfigPath = filepath.Join(tempDir, "config.yaml")
+		if err := os.WriteFile(AppConfigPath, []byte("paths:\n  - /config\n"), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if err := LoadConfig(); err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if !reflect.DeepEqual(AppConfig.Paths, []string{"/env"}) {
+			t.Errorf("LoadConfig() paths = %v, want [/env]", AppConfig.Paths)
+		}
+		if ConfigOrigins["paths"] != "env "+EnvPaths {
+			t.Errorf("LoadConfig() paths origin = %q, want env", ConfigOrigins["paths"])
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RepoInfo describes an indexed repo for prompts and status lines
+type RepoInfo struct {
+	Name   string   `json:"name"`
+	Path   string   `json:"path"`
+	Root   string   `json:"root"`
+	Groups []string `json:"groups,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+
+	Description string `json:"description,omitempty"`
+}
+
+// containsPath reports whether path is dir or lies below it
+func containsPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// repoGroups returns the sorted config groups whose patterns match the repo path
+func repoGroups(repoPath string) []string {
+	if AppConfig == nil {
+		return nil
+	}
+	var groups []string
+	for group, patterns := range AppConfig.Groups {
+		if inGroup(repoPath, patterns) {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// FindRepo returns the innermost indexed repo containing path, matching both the path it was
+// indexed under and its real location so symlinked checkouts resolve from either side
+func FindRepo(path string) (*RepoInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		real = abs
+	}
+
+	var found *Repo
+	for i, repo := range indexedRepos {
+		if !containsPath(repo.Path, abs) && !containsPath(repo.RealPath, real) {
+			continue
+		}
+		if found == nil || len(repo.RealPath) > len(found.RealPath) {
+			found = &indexedRepos[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: no indexed repository contains '%s'", ErrRepoNotFound, abs)
+	}
+
+	name := repoName(*found, naming())
+	if ReposMap[name] != found.Path {
+		// the name is shadowed by a duplicate, so show the path that still identifies it
+		name = found.Path
+	}
+
+	info := &RepoInfo{Name: name, Path: found.Path, Root: found.Root, Groups: repoGroups(found.Path)}
+	if found.Meta != nil {
+		info.Tags, info.Description = found.Meta.Tags, found.Meta.Description
+	}
+	return info, nil
+}
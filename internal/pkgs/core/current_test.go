@@ -0,0 +1,68 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindRepo(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "work/api/.git", "work/api/internal/handler", "web/.git", "web/lib/.git")
+
+	repos, _ := listGitRepos([]string{root}, false)
+	setupNamedRepos(t, NamingRelative, repos)
+	AppConfig.Groups = map[string][]string{"work": {filepath.Join(root, "work", "*")}, "all": {filepath.Join(root, "*", "*")}}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected RepoInfo
+	}{
+		{"repo top", filepath.Join(root, "work/api"), RepoInfo{Name: "work/api", Path: filepath.Join(root, "work/api"), Root: root, Groups: []string{"all", "work"}}},
+		{"subdirectory", filepath.Join(root, "work/api/internal/handler"), RepoInfo{Name: "work/api", Path: filepath.Join(root, "work/api"), Root: root, Groups: []string{"all", "work"}}},
+		{"nested repo wins", filepath.Join(root, "web/lib"), RepoInfo{Name: "web/lib", Path: filepath.Join(root, "web/lib"), Root: root, Groups: []string{"all"}}},
+		{"no groups", filepath.Join(root, "web"), RepoInfo{Name: "web", Path: filepath.Join(root, "web"), Root: root}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := FindRepo(tt.path)
+			if err != nil {
+				t.Fatalf("FindRepo() error = %v", err)
+			}
+			if !reflect.DeepEqual(*info, tt.expected) {
+				t.Errorf("FindRepo() = %+v, want %+v", *info, tt.expected)
+			}
+		})
+	}
+
+	if _, err := FindRepo(filepath.Join(root, "work")); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("FindRepo() outside repos error = %v, want ErrRepoNotFound", err)
+	}
+	if _, err := FindRepo(root + "/web-old"); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("FindRepo() on sibling with shared prefix error = %v, want ErrRepoNotFound", err)
+	}
+}
+
+func TestFindRepoThroughSymlink(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api/.git", "api/src")
+	link := filepath.Join(t.TempDir(), "api")
+	if err := os.Symlink(filepath.Join(root, "api"), link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	repos, _ := listGitRepos([]string{root}, false)
+	setupNamedRepos(t, NamingBasename, repos)
+
+	info, err := FindRepo(filepath.Join(link, "src"))
+	if err != nil {
+		t.Fatalf("FindRepo() error = %v", err)
+	}
+	if info.Name != "api" {
+		t.Errorf("FindRepo() name = %s, want api", info.Name)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// FieldPath describes a repo by its path under its root
+	FieldPath = "path"
+	// FieldBranch describes a repo by the branch checked out when it was last scanned
+	FieldBranch = "branch"
+	// FieldGroup describes a repo by the groups it belongs to
+	FieldGroup = "group"
+	// FieldTags describes a repo by the tags in its metadata
+	FieldTags = "tags"
+	// FieldDescription describes a repo by the description in its metadata
+	FieldDescription = "description"
+)
+
+// completionFields returns the configured completion fields, the relative path by default
+func completionFields() []string {
+	if AppConfig == nil || AppConfig.CompletionFields == nil {
+		return []string{FieldPath}
+	}
+	return AppConfig.CompletionFields
+}
+
+// validateCompletionFields rejects unknown completion fields
+func validateCompletionFields(fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case FieldPath, FieldBranch, FieldGroup, FieldTags, FieldDescription:
+		default:
+			return fmt.Errorf("invalid completion field '%s', expected %s, %s, %s, %s or %s", field, FieldPath, FieldBranch, FieldGroup, FieldTags, FieldDescription)
+		}
+	}
+	return nil
+}
+
+// readBranch returns the branch HEAD points at, or the abbreviated commit when detached
+func readBranch(path string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir(path), "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
+
+// describeRepo builds the one-line completion description of repo, indexed under name, only from the index
+// so it never touches the repo itself; empty when nothing is known beyond the name
+func describeRepo(repo *Repo, name string) string {
+	var parts []string
+	for _, field := range completionFields() {
+		var value string
+		switch field {
+		case FieldPath:
+			if rel, err := filepath.Rel(repo.Root, repo.Path); err == nil && repo.Root != "" {
+				value = filepath.ToSlash(rel)
+			} else {
+				value = repo.Path
+			}
+			if value == name {
+				value = ""
+			}
+		case FieldBranch:
+			value = repo.Branch
+		case FieldGroup:
+			value = strings.Join(repoGroups(repo.Path), ",")
+		case FieldTags:
+			if repo.Meta != nil {
+				value = strings.Join(repo.Meta.Tags, ",")
+			}
+		case FieldDescription:
+			if repo.Meta != nil {
+				value = repo.Meta.Description
+			}
+		}
+		// shells split candidates on tabs and newlines
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
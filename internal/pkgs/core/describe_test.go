@@ -0,0 +1,68 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateCompletionFields(t *testing.T) {
+	if err := validateCompletionFields([]string{FieldPath, FieldBranch, FieldGroup, FieldTags, FieldDescription}); err != nil {
+		t.Errorf("validateCompletionFields() error = %v", err)
+	}
+	if err := validateCompletionFields([]string{FieldPath, "remote"}); err == nil {
+		t.Error("validateCompletionFields() should reject unknown fields")
+	}
+}
+
+func TestReadBranch(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "branch/.git", "detached/.git", "worktree", "store/wt")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("branch/.git/HEAD", "ref: refs/heads/feature/x\n")
+	write("detached/.git/HEAD", "0123456789abcdef0123456789abcdef01234567\n")
+	write("worktree/.git", "gitdir: ../store/wt\n")
+	write("store/wt/HEAD", "ref: refs/heads/main\n")
+
+	tests := map[string]string{
+		"branch":   "feature/x",
+		"detached": "0123456",
+		"worktree": "main",
+		"missing":  "",
+	}
+	for dir, expected := range tests {
+		if branch := readBranch(filepath.Join(root, dir)); branch != expected {
+			t.Errorf("readBranch(%s) = %q, want %q", dir, branch, expected)
+		}
+	}
+}
+
+func TestDescribeRepo(t *testing.T) {
+	api := Repo{Path: "/src/work/api", Root: "/src", Branch: "main",
+		Meta: &RepoMeta{Tags: []string{"go", "http"}, Description: "Public\tAPI\nserver"}}
+	tool := Repo{Path: "/src/tool", Root: "/src"}
+	setupNamedRepos(t, NamingBasename, []Repo{api, tool})
+	AppConfig.Groups = map[string][]string{"work": {"/src/work/*"}}
+
+	if got := describeRepo(&api, "api"); got != "work/api" {
+		t.Errorf("describeRepo() default = %q, want relative path", got)
+	}
+	if got := describeRepo(&tool, "tool"); got != "" {
+		t.Errorf("describeRepo() = %q, want nothing when the path is the name", got)
+	}
+
+	AppConfig.CompletionFields = []string{FieldBranch, FieldGroup, FieldTags, FieldDescription}
+	if got, expected := describeRepo(&api, "api"), "main  work  go,http  Public API server"; got != expected {
+		t.Errorf("describeRepo() = %q, want %q", got, expected)
+	}
+
+	AppConfig.CompletionFields = []string{}
+	if got := describeRepo(&api, "api"); got != "" {
+		t.Errorf("describeRepo() with no fields = %q, want empty", got)
+	}
+}
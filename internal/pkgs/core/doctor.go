@@ -0,0 +1,272 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	cliBase "github.com/kahnwong/cli-base"
+)
+
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+type DoctorCheck struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+	Hint    string
+}
+
+// shellConfigFiles lists the files where shell integration is usually installed
+func shellConfigFiles() []string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome, _ = cliBase.ExpandHome("~/.config")
+	}
+	fishDir := filepath.Join(configHome, "fish")
+
+	files := []string{
+		filepath.Join(fishDir, "config.fish"),
+		filepath.Join(fishDir, "functions", "r.fish"),
+	}
+	if confD, err := filepath.Glob(filepath.Join(fishDir, "conf.d", "*.fish")); err == nil {
+		files = append(files, confD...)
+	}
+	for _, rc := range []string{"~/.bashrc", "~/.zshrc"} {
+		if path, err := cliBase.ExpandHome(rc); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// checkShellIntegration looks for repo-switcher in any shell config file
+func checkShellIntegration() DoctorCheck {
+	check := DoctorCheck{Name: "shell integration"}
+	for _, file := range shellConfigFiles() {
+		data, err := os.ReadFile(file)
+		if err == nil && strings.Contains(string(data), appName) {
+			check.Status = CheckOK
+			check.Message = "found in " + file
+			return check
+		}
+	}
+	check.Status = CheckWarn
+	check.Message = "no shell config references " + appName
+	check.Hint = "add the fish snippet from the README, or `source <(repo-switcher completion bash)` to your shell rc"
+	return check
+}
+
+// checkRoots verifies each configured root exists, is a readable directory and does not nest inside another
+func checkRoots(paths []string) []DoctorCheck {
+	var checks []DoctorCheck
+	var expanded []string
+
+	for _, root := range resolveRoots(paths) {
+		check := DoctorCheck{Name: "root " + root.Pattern}
+		if root.Err != nil {
+			check.Status, check.Message = CheckFail, root.Err.Error()
+			check.Hint = fmt.Sprintf("fix or remove %s in paths", root.Pattern)
+			checks = append(checks, check)
+			continue
+		}
+		dir := filepath.Clean(root.Path)
+
+		info, err := os.Stat(dir)
+		switch {
+		case os.IsNotExist(err):
+			check.Status, check.Message = CheckFail, dir+" does not exist"
+			check.Hint = fmt.Sprintf("create %s or remove it from paths", dir)
+		case err != nil:
+			check.Status, check.Message = CheckFail, err.Error()
+		case !info.IsDir():
+			check.Status, check.Message = CheckFail, dir+" is not a directory"
+			check.Hint = fmt.Sprintf("point paths at the directory containing your repositories instead of %s", dir)
+		default:
+			if _, err := os.ReadDir(dir); err != nil {
+				check.Status, check.Message = CheckFail, dir+" is not readable"
+				check.Hint = fmt.Sprintf("check permissions, e.g. chmod u+rx %s", dir)
+			} else {
+				check.Status, check.Message = CheckOK, dir
+				expanded = append(expanded, dir)
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	for i, outer := range expanded {
+		for j, inner := range expanded {
+			if i == j {
+				continue
+			}
+			rel, err := filepath.Rel(outer, inner)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if rel == "." && j < i {
+				// report identical roots once
+				continue
+			}
+			checks = append(checks, DoctorCheck{
+				Name:    "root overlap",
+				Status:  CheckWarn,
+				Message: fmt.Sprintf("%s is inside %s", inner, outer),
+				Hint:    fmt.Sprintf("remove %s from paths, repositories under it are already scanned through %s", inner, outer),
+			})
+		}
+	}
+
+	return checks
+}
+
+// duplicateNames groups repos that share a display name, so only the last one is reachable by name
+func duplicateNames(repos []Repo, mode string) map[string][]string {
+	byName := make(map[string][]string)
+	for _, repo := range repos {
+		name := repoName(repo, mode)
+		byName[name] = append(byName[name], repo.Path)
+	}
+	for name, paths := range byName {
+		if len(paths) < 2 {
+			delete(byName, name)
+		}
+	}
+	return byName
+}
+
+// checkDuplicates reports repo names that shadow each other in the name index
+func checkDuplicates(repos []Repo, mode string) []DoctorCheck {
+	duplicates := duplicateNames(repos, mode)
+	if len(duplicates) == 0 {
+		return []DoctorCheck{{Name: "repo names", Status: CheckOK, Message: fmt.Sprintf("%d unique names", len(repos))}}
+	}
+
+	names := make([]string, 0, len(duplicates))
+	for name := range duplicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []DoctorCheck
+	for _, name := range names {
+		paths := duplicates[name]
+		checks = append(checks, DoctorCheck{
+			Name:    "repo name " + name,
+			Status:  CheckWarn,
+			Message: fmt.Sprintf("used by %d repositories: %s", len(paths), strings.Join(paths, ", ")),
+			Hint:    fmt.Sprintf("only %s is reachable as '%s'; rename the other directories or set naming: %s", paths[len(paths)-1], name, NamingRelative),
+		})
+	}
+	return checks
+}
+
+// checkNameCollisions reports names that only resolve when typed exactly, because matching folds them together
+func checkNameCollisions(names []string) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, group := range nameCollisions(names) {
+		checks = append(checks, DoctorCheck{
+			Name:    "repo name " + group[0],
+			Status:  CheckWarn,
+			Message: fmt.Sprintf("matches %s after case, separator or Unicode folding", strings.Join(group[1:], ", ")),
+			Hint:    "type these names exactly, or rename the directories so they differ by more than case or separators",
+		})
+	}
+	return checks
+}
+
+// checkCommandCollisions reports repo names that are also subcommands, so typing them runs the command instead of switching
+func checkCommandCollisions(names, commands []string) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, name := range names {
+		if !containsString(commands, name) {
+			continue
+		}
+		checks = append(checks, DoctorCheck{
+			Name:    "repo name " + name,
+			Status:  CheckWarn,
+			Message: fmt.Sprintf("shadowed by the %s command", name),
+			Hint:    fmt.Sprintf("switch with `%s -- %s`, or set another name in the repo's %s", appName, name, metaFileName),
+		})
+	}
+	return checks
+}
+
+// checkCache validates the cache file and reports its age
+func checkCache(paths []string, followSymlinks bool) DoctorCheck {
+	check := DoctorCheck{Name: "cache"}
+
+	cache, err := readCache()
+	switch {
+	case os.IsNotExist(err):
+		check.Status, check.Message = CheckWarn, "no cache at "+cacheFilePath
+		check.Hint = "run `repo-switcher refresh`"
+	case err != nil:
+		check.Status, check.Message = CheckFail, fmt.Sprintf("%s is unreadable: %v", cacheFilePath, err)
+		check.Hint = fmt.Sprintf("delete %s and run `repo-switcher refresh`", cacheFilePath)
+	case !isCacheValid(cache, cacheKeys(resolveRoots(paths), followSymlinks)):
+		check.Status = CheckWarn
+		check.Message = fmt.Sprintf("stale, %d repositories, %s old", len(cache.Repos), time.Since(cache.Timestamp).Round(time.Second))
+		check.Hint = "run `repo-switcher refresh`"
+	default:
+		check.Status = CheckOK
+		check.Message = fmt.Sprintf("%d repositories, %s old", len(cache.Repos), time.Since(cache.Timestamp).Round(time.Second))
+	}
+	return check
+}
+
+// RunDoctor checks each step of loading and returns a report, stopping early when a step makes the rest meaningless.
+// commands are the subcommand names a repo name must not take.
+func RunDoctor(configFlag string, commands []string) []DoctorCheck {
+	if err := ResolvePaths(configFlag); err != nil {
+		return []DoctorCheck{{Name: "paths", Status: CheckFail, Message: err.Error()}}
+	}
+
+	checks := []DoctorCheck{}
+	if err := LoadConfig(); err != nil {
+		check := DoctorCheck{Name: "config", Status: CheckFail, Message: err.Error()}
+		if errors.Is(err, fs.ErrNotExist) {
+			check.Hint = fmt.Sprintf("create %s with a paths list, or point --config / $%s at your config", AppConfigPath, EnvConfig)
+		} else {
+			check.Hint = fmt.Sprintf("fix the YAML in %s (or one of its includes)", AppConfigPath)
+		}
+		return append(checks, check, checkShellIntegration())
+	}
+	checks = append(checks, DoctorCheck{Name: "config", Status: CheckOK, Message: AppConfigPath})
+
+	if len(AppConfig.Paths) == 0 {
+		checks = append(checks, DoctorCheck{
+			Name:    "roots",
+			Status:  CheckFail,
+			Message: "no paths configured",
+			Hint:    fmt.Sprintf("add the directories containing your repositories under paths in %s", AppConfigPath),
+		})
+	}
+	checks = append(checks, checkRoots(AppConfig.Paths)...)
+
+	cacheCheck := checkCache(AppConfig.Paths, AppConfig.FollowSymlinks)
+	checks = append(checks, cacheCheck)
+
+	var repos []Repo
+	if cache, err := readCache(); err == nil && cacheCheck.Status == CheckOK {
+		repos = cache.Repos
+	} else {
+		repos, _ = listGitRepos(AppConfig.Paths, AppConfig.FollowSymlinks)
+	}
+	checks = append(checks, checkDuplicates(repos, naming())...)
+	names := getReposName(buildReposMap(repos, naming()))
+	checks = append(checks, checkNameCollisions(names)...)
+	checks = append(checks, checkCommandCollisions(names, commands)...)
+
+	return append(checks, checkShellIntegration())
+}
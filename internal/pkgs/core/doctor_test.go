@@ -0,0 +1,243 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func checkStatuses(checks []DoctorCheck) map[string]CheckStatus {
+	statuses := make(map[string]CheckStatus)
+	for _, c := range checks {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestCheckRoots(t *testing.T) {
+	tempDir := t.TempDir()
+	root := filepath.Join(tempDir, "root")
+	nested := filepath.Join(root, "nested")
+	file := filepath.Join(tempDir, "file")
+	missing := filepath.Join(tempDir, "missing")
+
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	checks := checkRoots([]string{root, nested, file, missing})
+	statuses := checkStatuses(checks)
+
+	expected := map[string]CheckStatus{
+		"root " + root:    CheckOK,
+		"root " + nested:  CheckOK,
+		"root " + file:    CheckFail,
+		"root " + missing: CheckFail,
+		"root overlap":    CheckWarn,
+	}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("checkRoots() %s = %s, want %s", name, statuses[name], status)
+		}
+	}
+	for _, c := range checks {
+		if c.Status != CheckOK && c.Hint == "" {
+			t.Errorf("checkRoots() %s has no fix hint", c.Name)
+		}
+	}
+}
+
+func TestCheckRootsIdenticalReportedOnce(t *testing.T) {
+	root := t.TempDir()
+
+	var overlaps int
+	for _, c := range checkRoots([]string{root, root}) {
+		if c.Name == "root overlap" {
+			overlaps++
+		}
+	}
+	if overlaps != 1 {
+		t.Errorf("checkRoots() reported %d overlaps for identical roots, want 1", overlaps)
+	}
+}
+
+func TestCheckRootsDotDotSibling(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "..nested")
+	if err := os.Mkdir(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var overlaps int
+	for _, c := range checkRoots([]string{root, nested}) {
+		if c.Name == "root overlap" {
+			overlaps++
+		}
+	}
+	if overlaps != 1 {
+		t.Errorf("checkRoots() reported %d overlaps for a root named ..nested inside another, want 1", overlaps)
+	}
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	checks := checkDuplicates([]Repo{{Path: "/a/api"}, {Path: "/b/api"}, {Path: "/a/web"}}, NamingBasename)
+
+	if len(checks) != 1 {
+		t.Fatalf("checkDuplicates() returned %d checks, want 1", len(checks))
+	}
+	if checks[0].Name != "repo name api" || checks[0].Status != CheckWarn {
+		t.Errorf("checkDuplicates() = %+v, want warning for api", checks[0])
+	}
+	if !strings.Contains(checks[0].Hint, "/b/api") {
+		t.Errorf("checkDuplicates() hint = %q, want reachable path /b/api", checks[0].Hint)
+	}
+
+	checks = checkDuplicates([]Repo{{Path: "/a/api"}, {Path: "/a/web"}}, NamingBasename)
+	if len(checks) != 1 || checks[0].Status != CheckOK {
+		t.Errorf("checkDuplicates() = %+v, want single ok check", checks)
+	}
+
+	checks = checkDuplicates([]Repo{{Path: "/a/api", Root: "/a"}, {Path: "/b/api", Root: "/b"}, {Path: "/b/x/api", Root: "/b"}}, NamingRelative)
+	if len(checks) != 1 || checks[0].Name != "repo name api" {
+		t.Errorf("checkDuplicates() relative = %+v, want warning for api only", checks)
+	}
+}
+
+func TestCheckNameCollisions(t *testing.T) {
+	originalAppConfig := AppConfig
+	t.Cleanup(func() { AppConfig = originalAppConfig })
+
+	AppConfig = &Config{}
+	if checks := checkNameCollisions([]string{"API", "api"}); len(checks) != 0 {
+		t.Errorf("checkNameCollisions() = %+v, want none without ignore_case", checks)
+	}
+
+	AppConfig = &Config{IgnoreCase: true}
+	checks := checkNameCollisions([]string{"API", "api", "web"})
+	if len(checks) != 1 || checks[0].Name != "repo name API" || checks[0].Status != CheckWarn {
+		t.Errorf("checkNameCollisions() = %+v, want warning for API", checks)
+	}
+}
+
+func TestCheckCommandCollisions(t *testing.T) {
+	checks := checkCommandCollisions([]string{"api", "sync", "web"}, []string{"exec", "sync"})
+	if len(checks) != 1 || checks[0].Name != "repo name sync" || checks[0].Status != CheckWarn {
+		t.Errorf("checkCommandCollisions() = %+v, want warning for sync", checks)
+	}
+	if !strings.Contains(checks[0].Hint, "-- sync") {
+		t.Errorf("checkCommandCollisions() hint = %q, want the -- form", checks[0].Hint)
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	originalCachePath := cacheFilePath
+	defer func() { cacheFilePath = originalCachePath }()
+
+	paths := []string{"/home/user/projects"}
+	cacheFilePath = filepath.Join(t.TempDir(), cacheFileName)
+
+	if c := checkCache(paths, false); c.Status != CheckWarn {
+		t.Errorf("checkCache() missing cache = %s, want %s", c.Status, CheckWarn)
+	}
+
+	if err := writeCache([]Repo{{Path: "/home/user/projects/repo1", RealPath: "/home/user/projects/repo1"}}, paths); err != nil {
+		t.Fatalf("writeCache() error = %v", err)
+	}
+	if c := checkCache(paths, false); c.Status != CheckOK {
+		t.Errorf("checkCache() valid cache = %s (%s), want %s", c.Status, c.Message, CheckOK)
+	}
+	if c := checkCache([]string{"/other"}, false); c.Status != CheckWarn {
+		t.Errorf("checkCache() stale cache = %s, want %s", c.Status, CheckWarn)
+	}
+
+	if err := os.WriteFile(cacheFilePath, []byte("invalid json"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if c := checkCache(paths, false); c.Status != CheckFail {
+		t.Errorf("checkCache() corrupt cache = %s, want %s", c.Status, CheckFail)
+	}
+}
+
+func TestCheckShellIntegration(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	if c := checkShellIntegration(); c.Status != CheckWarn {
+		t.Errorf("checkShellIntegration() without config = %s, want %s", c.Status, CheckWarn)
+	}
+
+	fishConfig := filepath.Join(home, ".config", "fish", "config.fish")
+	writeConfigFile(t, fishConfig, "repo-switcher completion fish | source\n")
+
+	if c := checkShellIntegration(); c.Status != CheckOK {
+		t.Errorf("checkShellIntegration() with fish config = %s, want %s", c.Status, CheckOK)
+	}
+}
+
+func TestRunDoctorMissingConfig(t *testing.T) {
+	originalCachePath := cacheFilePath
+	originalAppConfig := AppConfig
+	defer func() {
+		cacheFilePath = originalCachePath
+		AppConfig = originalAppConfig
+	}()
+
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CACHE_HOME", tempDir)
+	t.Setenv(EnvPaths, "")
+
+	checks := RunDoctor(filepath.Join(tempDir, "missing.yaml"), nil)
+	statuses := checkStatuses(checks)
+	if statuses["config"] != CheckFail {
+		t.Errorf("RunDoctor() config = %s, want %s", statuses["config"], CheckFail)
+	}
+	for _, c := range checks {
+		if c.Name == "config" && !strings.Contains(c.Hint, "missing.yaml") {
+			t.Errorf("RunDoctor() config hint = %q, want config path", c.Hint)
+		}
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	originalCachePath := cacheFilePath
+	originalAppConfig := AppConfig
+	defer func() {
+		cacheFilePath = originalCachePath
+		AppConfig = originalAppConfig
+	}()
+
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, "cache"))
+	t.Setenv(EnvPaths, "")
+
+	root := filepath.Join(tempDir, "Git")
+	for _, repo := range []string{"a/api/.git", "b/api/.git", "c/sync/.git"} {
+		if err := os.MkdirAll(filepath.Join(root, repo), 0755); err != nil {
+			t.Fatalf("failed to create test directory: %v", err)
+		}
+	}
+	configPath := filepath.Join(tempDir, "config.yaml")
+	writeConfigFile(t, configPath, "paths:\n  - "+root+"\n")
+
+	statuses := checkStatuses(RunDoctor(configPath, []string{"exec", "sync"}))
+
+	expected := map[string]CheckStatus{
+		"config":         CheckOK,
+		"root " + root:   CheckOK,
+		"cache":          CheckWarn,
+		"repo name api":  CheckWarn,
+		"repo name sync": CheckWarn,
+	}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("RunDoctor() %s = %s, want %s", name, statuses[name], status)
+		}
+	}
+}
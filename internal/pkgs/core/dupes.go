@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DupeCopy is one clone within a group of duplicates
+type DupeCopy struct {
+	Name   string
+	Path   string
+	Branch string
+	Dirty  bool
+	// LastActivity is the later of the last commit and the last change to the index
+	LastActivity time.Time
+	Err          error
+}
+
+// DupeGroup is a set of indexed repos that are clones of the same project
+type DupeGroup struct {
+	// Remote is the normalised origin URL of the copies, empty when only the history matches
+	Remote string
+	// RootCommit is the first commit the copies share, empty when only the remote matches
+	RootCommit string
+	// Copies are ordered by last activity, most recent first
+	Copies []DupeCopy
+}
+
+// repoIdentity is what a repo is matched on, plus the state shown for each copy
+type repoIdentity struct {
+	origin      string
+	rootCommits []string
+	copy        DupeCopy
+}
+
+// inspectRepo reads the identity and state of the repo at path
+func inspectRepo(ctx context.Context, path string) repoIdentity {
+	id := repoIdentity{copy: DupeCopy{Name: DisplayName(path), Path: path}}
+
+	remotes, err := listRemotes(ctx, path)
+	if err != nil {
+		id.copy.Err = err
+		return id
+	}
+	// other remotes such as upstream point at related but separate projects
+	if u, ok := remotes["origin"]; ok {
+		id.origin = normalizeRemoteURL(u)
+	}
+
+	// repos without commits have no root and no branch yet
+	if roots, err := runGit(ctx, path, "rev-list", "--max-parents=0", "HEAD"); err == nil {
+		id.rootCommits = strings.Fields(roots)
+	}
+	if branch, err := runGit(ctx, path, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		id.copy.Branch = branch
+	}
+	if status, err := runGit(ctx, path, "status", "--porcelain"); err == nil {
+		id.copy.Dirty = status != ""
+	}
+	if ts, err := runGit(ctx, path, "log", "-1", "--format=%ct"); err == nil {
+		if sec, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			id.copy.LastActivity = time.Unix(sec, 0)
+		}
+	}
+	if gitDir, err := runGit(ctx, path, "rev-parse", "--absolute-git-dir"); err == nil {
+		if info, err := os.Stat(filepath.Join(gitDir, "index")); err == nil && info.ModTime().After(id.copy.LastActivity) {
+			id.copy.LastActivity = info.ModTime()
+		}
+	}
+	return id
+}
+
+// FindDupes groups indexed repos, including ones whose names are shadowed, that are clones of one project.
+// Repos are grouped by normalised origin URL, so forks and repos that only track another as upstream stay apart.
+// A shared root commit is only supporting evidence: a repo without an origin joins the one group whose history
+// it shares, or other origin-less repos with the same root commits. Only groups with more than one copy are returned.
+func FindDupes(ctx context.Context, jobs int) []DupeGroup {
+	repos := indexedRepos
+	ids := make([]repoIdentity, len(repos))
+	forEachParallel(ctx, len(repos), jobs, func(ctx context.Context, i int) {
+		ids[i] = inspectRepo(ctx, repos[i].Path)
+	})
+
+	var members [][]int
+	origins := make(map[int]string)
+	groupOf := make(map[string]int)
+	add := func(key string, i int) int {
+		g, ok := groupOf[key]
+		if !ok {
+			g = len(members)
+			groupOf[key] = g
+			members = append(members, nil)
+		}
+		members[g] = append(members[g], i)
+		return g
+	}
+	for i, id := range ids {
+		if id.origin != "" {
+			origins[add("origin "+id.origin, i)] = id.origin
+		}
+	}
+
+	// root commits of each origin group, -1 when several groups share one, as forks do
+	rootGroup := make(map[string]int)
+	for g, indexes := range members {
+		for _, i := range indexes {
+			for _, commit := range ids[i].rootCommits {
+				if prev, ok := rootGroup[commit]; ok && prev != g {
+					rootGroup[commit] = -1
+				} else {
+					rootGroup[commit] = g
+				}
+			}
+		}
+	}
+	for i, id := range ids {
+		if id.origin != "" || len(id.rootCommits) == 0 {
+			continue
+		}
+		match := -1
+		for _, commit := range id.rootCommits {
+			g, ok := rootGroup[commit]
+			if !ok {
+				continue
+			}
+			if g < 0 || (match >= 0 && match != g) {
+				match = -1
+				break
+			}
+			match = g
+		}
+		if match >= 0 {
+			members[match] = append(members[match], i)
+			continue
+		}
+		roots := append([]string(nil), id.rootCommits...)
+		sort.Strings(roots)
+		add("root "+strings.Join(roots, " "), i)
+	}
+
+	var groups []DupeGroup
+	for g, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		group := DupeGroup{
+			Remote:     origins[g],
+			RootCommit: sharedValue(ids, indexes, func(id repoIdentity) []string { return id.rootCommits }),
+		}
+		for _, i := range indexes {
+			group.Copies = append(group.Copies, ids[i].copy)
+		}
+		sort.Slice(group.Copies, func(a, b int) bool {
+			if !group.Copies[a].LastActivity.Equal(group.Copies[b].LastActivity) {
+				return group.Copies[a].LastActivity.After(group.Copies[b].LastActivity)
+			}
+			return group.Copies[a].Path < group.Copies[b].Path
+		})
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(a, b int) bool {
+		if groups[a].Remote != groups[b].Remote {
+			return groups[a].Remote < groups[b].Remote
+		}
+		return groups[a].RootCommit < groups[b].RootCommit
+	})
+	return groups
+}
+
+// sharedValue returns the smallest value that every member has, or "" when there is none
+func sharedValue(ids []repoIdentity, indexes []int, values func(repoIdentity) []string) string {
+	counts := make(map[string]int)
+	for _, i := range indexes {
+		seen := make(map[string]bool)
+		for _, v := range values(ids[i]) {
+			if !seen[v] {
+				seen[v] = true
+				counts[v]++
+			}
+		}
+	}
+
+	var shared []string
+	for v, n := range counts {
+		if n == len(indexes) {
+			shared = append(shared, v)
+		}
+	}
+	sort.Strings(shared)
+	if len(shared) == 0 {
+		return ""
+	}
+	return shared[0]
+}
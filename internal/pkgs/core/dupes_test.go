@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindDupes(t *testing.T) {
+	remote, first := newRemoteAndClone(t)
+	second := cloneRemote(t, remote)
+
+	// forks share the history but are separate projects, even when they track the original as upstream
+	fork := cloneRemote(t, remote)
+	gitCmd(t, fork, "remote", "rename", "origin", "upstream")
+	gitCmd(t, fork, "remote", "add", "origin", "git@github.com:someone/fork.git")
+	otherFork := cloneRemote(t, remote)
+	gitCmd(t, otherFork, "remote", "set-url", "origin", "git@github.com:other/fork.git")
+
+	unrelated := filepath.Join(t.TempDir(), "unrelated")
+	gitCmd(t, filepath.Dir(unrelated), "init", "-q", unrelated)
+	commitFile(t, unrelated, "a.txt", "a\n")
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	gitCmd(t, filepath.Dir(empty), "init", "-q", empty)
+
+	gitCmd(t, second, "checkout", "-q", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(second, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// make the first clone the most recently active one
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(first, ".git", "index"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: first}, {Path: second}, {Path: fork}, {Path: otherFork}, {Path: unrelated}, {Path: empty}})
+
+	groups := FindDupes(context.Background(), 2)
+	if len(groups) != 1 {
+		t.Fatalf("FindDupes() returned %d groups, want 1: %+v", len(groups), groups)
+	}
+	group := groups[0]
+	if len(group.Copies) != 2 {
+		t.Fatalf("group has %d copies, want the two clones: %+v", len(group.Copies), group.Copies)
+	}
+	for _, c := range group.Copies {
+		if c.Path == fork || c.Path == otherFork {
+			t.Errorf("group includes fork %s", c.Path)
+		}
+	}
+	if group.RootCommit == "" {
+		t.Error("group should share a root commit")
+	}
+	if group.Remote != normalizeRemoteURL(remote) {
+		t.Errorf("group remote = %s, want the clones' origin", group.Remote)
+	}
+
+	if group.Copies[0].Path != first || !group.Copies[0].LastActivity.After(time.Now()) {
+		t.Errorf("copies[0] = %+v, want the most recently active clone first", group.Copies[0])
+	}
+	for _, c := range group.Copies {
+		if c.Path == second && (c.Branch != "feature" || !c.Dirty) {
+			t.Errorf("second clone = %+v, want dirty on feature", c)
+		}
+		if c.Path == first && (c.Branch != "main" || c.Dirty) {
+			t.Errorf("first clone = %+v, want clean on main", c)
+		}
+	}
+}
+
+func TestFindDupesWithoutOrigin(t *testing.T) {
+	remote, first := newRemoteAndClone(t)
+	second := cloneRemote(t, remote)
+
+	// a copy without an origin joins the one project whose history it shares
+	detached := cloneRemote(t, remote)
+	gitCmd(t, detached, "remote", "remove", "origin")
+
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: first}, {Path: second}, {Path: detached}})
+	groups := FindDupes(context.Background(), 2)
+	if len(groups) != 1 || len(groups[0].Copies) != 3 || groups[0].Remote != normalizeRemoteURL(remote) {
+		t.Errorf("FindDupes() = %+v, want the copy without origin grouped with the clones", groups)
+	}
+}
+
+func TestFindDupesAmbiguousHistory(t *testing.T) {
+	remote, _ := newRemoteAndClone(t)
+	fork := cloneRemote(t, remote)
+	gitCmd(t, fork, "remote", "set-url", "origin", "git@github.com:someone/fork.git")
+	original := cloneRemote(t, remote)
+
+	// the history matches both projects, so it is not guessed into either
+	detached := cloneRemote(t, remote)
+	gitCmd(t, detached, "remote", "remove", "origin")
+
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: fork}, {Path: original}, {Path: detached}})
+	if groups := FindDupes(context.Background(), 2); len(groups) != 0 {
+		t.Errorf("FindDupes() = %+v, want no groups", groups)
+	}
+}
+
+func TestFindDupesByRemote(t *testing.T) {
+	requireGit(t)
+	var repos []Repo
+	for _, name := range []string{"a", "b"} {
+		dir := filepath.Join(t.TempDir(), name)
+		gitCmd(t, filepath.Dir(dir), "init", "-q", dir)
+		gitCmd(t, dir, "remote", "add", "origin", map[string]string{
+			"a": "https://github.com/kahnwong/dotfiles.git",
+			"b": "git@github.com:kahnwong/dotfiles",
+		}[name])
+		repos = append(repos, Repo{Path: dir})
+	}
+	setupNamedRepos(t, NamingBasename, repos)
+
+	groups := FindDupes(context.Background(), 1)
+	if len(groups) != 1 || groups[0].Remote != "github.com/kahnwong/dotfiles" || len(groups[0].Copies) != 2 {
+		t.Errorf("FindDupes() = %+v, want both empty clones grouped by remote", groups)
+	}
+}
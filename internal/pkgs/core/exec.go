@@ -0,0 +1,156 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+type ExecOptions struct {
+	Jobs     int
+	FailFast bool
+	// Output receives each repo's output line by line, prefixed with the repo name.
+	// When nil, output is collected into ExecResult.Output instead.
+	Output io.Writer
+}
+
+type ExecResult struct {
+	Name     string
+	Path     string
+	Output   []byte
+	ExitCode int
+	Skipped  bool
+	Err      error
+}
+
+// forEachParallel calls fn for every index in [0, n) using at most jobs goroutines
+func forEachParallel(ctx context.Context, n, jobs int, fn func(ctx context.Context, i int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(ctx, i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// prefixWriter writes complete lines to out, each prefixed with the repo name
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix []byte
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(w.prefix); err != nil {
+		return err
+	}
+	_, err := w.out.Write(line)
+	return err
+}
+
+// ExecInRepos runs argv inside each selected repo with bounded parallelism.
+// Results are returned in the same order as repos.
+func ExecInRepos(ctx context.Context, repos []SelectedRepo, argv []string, opts ExecOptions) []ExecResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]ExecResult, len(repos))
+	var outMu sync.Mutex
+
+	forEachParallel(ctx, len(repos), opts.Jobs, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Name, result.Path = repos[i].Name, repos[i].Path
+
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.ExitCode = -1
+			return
+		}
+
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Dir = result.Path
+
+		var collected bytes.Buffer
+		var pw *prefixWriter
+		if opts.Output != nil {
+			pw = &prefixWriter{mu: &outMu, out: opts.Output, prefix: []byte(result.Name + " | ")}
+			cmd.Stdout = pw
+			cmd.Stderr = pw
+		} else {
+			cmd.Stdout = &collected
+			cmd.Stderr = &collected
+		}
+
+		err := cmd.Run()
+		if pw != nil {
+			_ = pw.Flush()
+		}
+		result.Output = collected.Bytes()
+
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			result.ExitCode = 0
+		case errors.As(err, &exitErr):
+			result.ExitCode = exitErr.ExitCode()
+			if result.ExitCode == -1 && ctx.Err() != nil {
+				result.Err = ctx.Err()
+			}
+		default:
+			result.ExitCode = -1
+			result.Err = err
+		}
+
+		if result.ExitCode != 0 && opts.FailFast {
+			cancel()
+		}
+	})
+
+	return results
+}
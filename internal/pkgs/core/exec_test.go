@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func setupExecRepos(t *testing.T, names ...string) []SelectedRepo {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	var repos []SelectedRepo
+	for _, name := range names {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("failed to create test directory %s: %v", path, err)
+		}
+		repos = append(repos, SelectedRepo{Name: name, Path: path})
+	}
+	return repos
+}
+
+func TestExecInReposCollect(t *testing.T) {
+	repos := setupExecRepos(t, "repo1", "repo2")
+
+	results := ExecInRepos(context.Background(), repos, []string{"sh", "-c", "basename \"$PWD\""}, ExecOptions{Jobs: 2})
+
+	if len(results) != 2 {
+		t.Fatalf("ExecInRepos() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			t.Errorf("ExecInRepos() %s exit code = %d, want 0", r.Name, r.ExitCode)
+		}
+		if strings.TrimSpace(string(r.Output)) != r.Name {
+			t.Errorf("ExecInRepos() %s output = %q, want %q", r.Name, r.Output, r.Name)
+		}
+	}
+}
+
+func TestExecInReposExitCodes(t *testing.T) {
+	repos := setupExecRepos(t, "ok", "fail")
+
+	results := ExecInRepos(context.Background(), repos, []string{"sh", "-c", "test \"$(basename \"$PWD\")\" = ok || exit 3"}, ExecOptions{Jobs: 2})
+
+	if results[0].ExitCode != 0 {
+		t.Errorf("ExecInRepos() ok exit code = %d, want 0", results[0].ExitCode)
+	}
+	if results[1].ExitCode != 3 {
+		t.Errorf("ExecInRepos() fail exit code = %d, want 3", results[1].ExitCode)
+	}
+}
+
+func TestExecInReposCommandNotFound(t *testing.T) {
+	repos := setupExecRepos(t, "repo1")
+
+	results := ExecInRepos(context.Background(), repos, []string{"this-command-does-not-exist-hopefully"}, ExecOptions{Jobs: 1})
+
+	if results[0].Err == nil {
+		t.Error("ExecInRepos() expected error for missing command, got nil")
+	}
+	if results[0].ExitCode != -1 {
+		t.Errorf("ExecInRepos() exit code = %d, want -1", results[0].ExitCode)
+	}
+}
+
+func TestExecInReposFailFast(t *testing.T) {
+	repos := setupExecRepos(t, "a", "b", "c")
+
+	results := ExecInRepos(context.Background(), repos, []string{"false"}, ExecOptions{Jobs: 1, FailFast: true})
+
+	if results[0].ExitCode == 0 || results[0].Skipped {
+		t.Errorf("ExecInRepos() first repo should have run and failed, got %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if !r.Skipped {
+			t.Errorf("ExecInRepos() %s should be skipped after fail-fast", r.Name)
+		}
+	}
+}
+
+func TestExecInReposPrefixOutput(t *testing.T) {
+	repos := setupExecRepos(t, "repo1", "repo2")
+
+	var out bytes.Buffer
+	ExecInRepos(context.Background(), repos, []string{"printf", "one\ntwo"}, ExecOptions{Jobs: 2, Output: &out})
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("ExecInRepos() wrote %d lines, want 4: %q", len(lines), out.String())
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "repo1 | ") && !strings.HasPrefix(line, "repo2 | ") {
+			t.Errorf("ExecInRepos() line missing repo prefix: %q", line)
+		}
+	}
+}
+
+func TestForEachParallel(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	forEachParallel(context.Background(), 10, 3, func(ctx context.Context, i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[i] = true
+	})
+
+	if len(seen) != 10 {
+		t.Errorf("forEachParallel() visited %d indexes, want 10", len(seen))
+	}
+}
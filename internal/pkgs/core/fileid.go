@@ -0,0 +1,28 @@
+//go:build !windows
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID identifies a directory independently of the path used to reach it
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// dirID returns the device and inode of path, following symlinks
+func dirID(path string) (fileID, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileID{}, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, fmt.Errorf("no inode information for %s", path)
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, nil
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"path/filepath"
+)
+
+// fileID identifies a directory independently of the path used to reach it
+type fileID struct {
+	path string
+}
+
+// dirID falls back to the resolved path, as inodes are not exposed on Windows
+func dirID(path string) (fileID, error) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fileID{}, err
+	}
+	return fileID{path: realPath}, nil
+}
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteRepo is a repository listed by a forge
+type RemoteRepo struct {
+	Forge       string `json:"forge"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	CloneURL    string `json:"clone_url"`
+	SSHURL      string `json:"ssh_url,omitempty"`
+	WebURL      string `json:"web_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+	Fork        bool   `json:"fork,omitempty"`
+	// LocalPath is the indexed clone of the repo, empty when it is not cloned
+	LocalPath string `json:"local_path,omitempty"`
+}
+
+// ForgeConfig configures one forge account to list repositories from
+type ForgeConfig struct {
+	// Name identifies the forge in commands, defaulting to its type
+	Name string `yaml:"name,omitempty"`
+	Type string `yaml:"type"`
+	// URL is the API base URL, for self-hosted instances
+	URL   string `yaml:"url,omitempty"`
+	Owner string `yaml:"owner"`
+	// TokenEnv names the environment variable holding an API token
+	TokenEnv string `yaml:"token_env,omitempty"`
+	// Root is where clones go as <root>/<host>/<owner>/<repo>, defaulting to the first path
+	Root string `yaml:"root,omitempty"`
+	// Protocol picks the clone URL: https (default) or ssh
+	Protocol string `yaml:"protocol,omitempty"`
+}
+
+func (f ForgeConfig) name() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Type
+}
+
+// validateForges rejects forges with unknown types or protocols, no owner, or clashing names
+func validateForges(forges []ForgeConfig) error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, forge := range forges {
+		if _, ok := defaultForgeURLs[forge.Type]; !ok {
+			errs = append(errs, fmt.Errorf("forges[%d]: invalid type '%s', expected %s, %s or %s", i, forge.Type, ForgeGitHub, ForgeGitLab, ForgeGitea))
+		}
+		if forge.Owner == "" {
+			errs = append(errs, fmt.Errorf("forges[%d]: owner is empty", i))
+		}
+		switch forge.Protocol {
+		case "", "https", "ssh":
+		default:
+			errs = append(errs, fmt.Errorf("forges[%d]: invalid protocol '%s', expected https or ssh", i, forge.Protocol))
+		}
+		if seen[forge.name()] {
+			errs = append(errs, fmt.Errorf("forges[%d]: name '%s' is used twice, set name to tell them apart", i, forge.name()))
+		}
+		seen[forge.name()] = true
+	}
+	return errors.Join(errs...)
+}
+
+// parseRemoteURL splits https, ssh and scp-style remote URLs into host and repo path, dropping users
+// and the .git suffix. http(s) hosts keep their port, which web URLs need; ssh ports are dropped.
+// ok is false for local paths.
+func parseRemoteURL(raw string) (host, path string, ok bool) {
+	raw = strings.TrimSpace(raw)
+
+	if u, err := url.Parse(raw); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Host, u.Path
+		if u.Scheme != "http" && u.Scheme != "https" {
+			host = u.Hostname()
+		}
+	} else if at := strings.Index(raw, ":"); at > 0 && !strings.Contains(raw[:at], "/") {
+		// scp-like syntax: [user@]host:path
+		host, path = raw[:at], raw[at+1:]
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		return "", "", false
+	}
+
+	return host, strings.TrimSuffix(strings.Trim(path, "/"), ".git"), true
+}
+
+// normalizeRemoteURL reduces remote URLs to lowercase host/path, so the same repo compares equal
+func normalizeRemoteURL(raw string) string {
+	host, path, ok := parseRemoteURL(raw)
+	if !ok {
+		return strings.TrimSuffix(strings.TrimSpace(raw), ".git")
+	}
+	return strings.ToLower(stripPort(host) + "/" + path)
+}
+
+// stripPort returns host without its port, so clones over different ports compare equal
+func stripPort(host string) string {
+	return (&url.URL{Host: host}).Hostname()
+}
+
+// indexedRemotes maps the normalised remote URLs of every indexed repo to its path
+func indexedRemotes(ctx context.Context) map[string]string {
+	var mu sync.Mutex
+	remotes := make(map[string]string)
+
+	forEachParallel(ctx, len(indexedRepos), 8, func(ctx context.Context, i int) {
+		urls, err := listRemotes(ctx, indexedRepos[i].Path)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, u := range urls {
+			remotes[normalizeRemoteURL(u)] = indexedRepos[i].Path
+		}
+	})
+	return remotes
+}
+
+// markCloned sets LocalPath on every remote repo that an indexed repo has as a remote
+func markCloned(repos []RemoteRepo, remotes map[string]string) {
+	for i := range repos {
+		repos[i].LocalPath = ""
+		for _, u := range []string{repos[i].CloneURL, repos[i].SSHURL} {
+			if path, ok := remotes[normalizeRemoteURL(u)]; ok && u != "" {
+				repos[i].LocalPath = path
+				break
+			}
+		}
+	}
+}
+
+const forgeCacheFileName = "forges-cache.json"
+
+type forgeCache struct {
+	Repos     []RemoteRepo `json:"repos"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// forgeCachePath keeps forge listings next to the repo cache
+func forgeCachePath() string {
+	return filepath.Join(filepath.Dir(cacheFilePath), forgeCacheFileName)
+}
+
+// readForgeCache returns the last forge listing, or nil when there is none
+func readForgeCache() []RemoteRepo {
+	data, err := os.ReadFile(forgeCachePath())
+	if err != nil {
+		return nil
+	}
+	var cache forgeCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil
+	}
+	return cache.Repos
+}
+
+func writeForgeCache(repos []RemoteRepo) error {
+	data, err := json.MarshalIndent(forgeCache{Repos: repos, Timestamp: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(forgeCachePath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(forgeCachePath(), data, 0644)
+}
+
+// configuredForge returns the forge config with the given name
+func configuredForge(name string) (ForgeConfig, bool) {
+	if AppConfig != nil {
+		for _, forge := range AppConfig.Forges {
+			if forge.name() == name {
+				return forge, true
+			}
+		}
+	}
+	return ForgeConfig{}, false
+}
+
+// ListForgeRepos lists the repos of the named forge, or every configured forge when name is empty,
+// marks the ones already cloned and caches the listing for completion
+func ListForgeRepos(ctx context.Context, name string) ([]RemoteRepo, error) {
+	if AppConfig == nil || len(AppConfig.Forges) == 0 {
+		return nil, fmt.Errorf("no forges configured")
+	}
+	if _, ok := configuredForge(name); name != "" && !ok {
+		return nil, fmt.Errorf("forge '%s' is not defined in config", name)
+	}
+
+	var listed []RemoteRepo
+	var errs []error
+	refreshed := make(map[string]bool)
+	for _, forge := range AppConfig.Forges {
+		if name != "" && forge.name() != name {
+			continue
+		}
+		provider, err := NewProvider(forge.Type, forge.URL, os.Getenv(forge.TokenEnv))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		repos, err := provider.ListRepos(ctx, forge.Owner)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", forge.name(), err))
+			continue
+		}
+		for i := range repos {
+			repos[i].Forge = forge.name()
+		}
+		listed = append(listed, repos...)
+		refreshed[forge.name()] = true
+	}
+
+	markCloned(listed, indexedRemotes(ctx))
+	sort.Slice(listed, func(i, j int) bool {
+		if listed[i].Forge != listed[j].Forge {
+			return listed[i].Forge < listed[j].Forge
+		}
+		return listed[i].FullName < listed[j].FullName
+	})
+
+	// keep cached listings of forges that were not asked for, or that failed
+	cached := listed
+	for _, repo := range readForgeCache() {
+		if _, ok := configuredForge(repo.Forge); ok && !refreshed[repo.Forge] {
+			cached = append(cached, repo)
+		}
+	}
+	if err := writeForgeCache(cached); err != nil {
+		errs = append(errs, fmt.Errorf("failed to write forge cache: %w", err))
+	}
+
+	return listed, errors.Join(errs...)
+}
+
+// uncloned returns the cached forge repos that have no indexed clone
+func uncloned() []RemoteRepo {
+	var repos []RemoteRepo
+	for _, repo := range readForgeCache() {
+		// a clone that has since been deleted is offered again
+		if repo.LocalPath == "" || indexedRepo(repo.LocalPath) == nil {
+			if _, ok := configuredForge(repo.Forge); ok {
+				repos = append(repos, repo)
+			}
+		}
+	}
+	return repos
+}
+
+// RemoteCandidates returns the full names of cached forge repos that are not cloned, for completion.
+// Only the cache is read, so completion never waits on the network.
+func RemoteCandidates() []string {
+	var names []string
+	for _, repo := range uncloned() {
+		if _, ok := ReposMap[repo.FullName]; !ok {
+			names = append(names, repo.FullName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindRemoteRepo looks up an uncloned forge repo by full name, or by an unambiguous short name
+func FindRemoteRepo(name string) (*RemoteRepo, error) {
+	key := matchKey(name)
+	var byName []RemoteRepo
+	for _, repo := range uncloned() {
+		if matchKey(repo.FullName) == key {
+			return &repo, nil
+		}
+		if matchKey(repo.Name) == key {
+			byName = append(byName, repo)
+		}
+	}
+
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("%w: '%s'", ErrRepoNotFound, name)
+	case 1:
+		return &byName[0], nil
+	}
+	candidates := make([]string, len(byName))
+	for i, repo := range byName {
+		candidates[i] = repo.FullName
+	}
+	sort.Strings(candidates)
+	return nil, &AmbiguousError{Name: name, Candidates: candidates}
+}
+
+// cloneTarget returns where a forge repo is cloned: <root>/<host>/<full name>
+func cloneTarget(forge ForgeConfig, repo RemoteRepo) (string, error) {
+	root := forge.Root
+	if root == "" {
+		if len(AppConfig.Paths) == 0 {
+			return "", fmt.Errorf("no paths configured to clone into")
+		}
+		root = staticPrefix(AppConfig.Paths[0])
+	}
+	expanded, err := expandPath(root)
+	if err != nil {
+		return "", err
+	}
+
+	// the web URL names the forge's host even when cloning through an alias or mirror
+	source := repo.WebURL
+	if source == "" {
+		source = repo.CloneURL
+	}
+	host := strings.SplitN(normalizeRemoteURL(source), "/", 2)[0]
+	// both come from the forge's API response, so they must not lead out of the root
+	hostDir, err := joinWithin(expanded, host)
+	if err != nil {
+		return "", fmt.Errorf("refusing to clone %s: %w", repo.FullName, err)
+	}
+	target, err := joinWithin(hostDir, repo.FullName)
+	if err != nil {
+		return "", fmt.Errorf("refusing to clone %s: %w", repo.FullName, err)
+	}
+	if err := checkScanReach(target); err != nil {
+		return "", fmt.Errorf("refusing to clone %s: %w", repo.FullName, err)
+	}
+	return target, nil
+}
+
+// checkScanReach fails when a repo at dir would not be found by a scan, so a clone never lands
+// where the index can't see it: dir must lie under a configured root with its .git within maxScanDepth
+func checkScanReach(dir string) error {
+	var tooDeep string
+	for _, root := range resolveRoots(AppConfig.Paths) {
+		if root.Err != nil || !containsPath(root.Path, dir) {
+			continue
+		}
+		rel, err := filepath.Rel(root.Path, dir)
+		if err != nil {
+			continue
+		}
+		if len(strings.Split(rel, string(filepath.Separator)))+1 <= maxScanDepth {
+			return nil
+		}
+		tooDeep = root.Path
+	}
+	if tooDeep != "" {
+		return fmt.Errorf("%s is more than %d levels below root %s and would not be indexed", dir, maxScanDepth-1, tooDeep)
+	}
+	return fmt.Errorf("%s is not under any configured path and would not be indexed, set root on the forge", dir)
+}
+
+// CloneRemoteRepo clones a forge repo into the canonical layout and refreshes the index to include it
+func CloneRemoteRepo(ctx context.Context, repo RemoteRepo) (string, error) {
+	forge, ok := configuredForge(repo.Forge)
+	if !ok {
+		return "", fmt.Errorf("forge '%s' is not defined in config", repo.Forge)
+	}
+	target, err := cloneTarget(forge, repo)
+	if err != nil {
+		return "", err
+	}
+
+	cloneURL := repo.CloneURL
+	if forge.Protocol == "ssh" && repo.SSHURL != "" {
+		cloneURL = repo.SSHURL
+	}
+	status, err := restoreRepo(ctx, ManifestRepo{Remotes: map[string]string{"origin": cloneURL}}, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repo.FullName, err)
+	}
+	if status == RestorePresent {
+		return "", fmt.Errorf("%s already exists but is not indexed", target)
+	}
+
+	if _, err := RefreshCache(false); err != nil {
+		return target, err
+	}
+
+	// remember the clone so completion stops offering it before the next listing
+	cached := readForgeCache()
+	for i := range cached {
+		if cached[i].Forge == repo.Forge && cached[i].FullName == repo.FullName {
+			cached[i].LocalPath = target
+		}
+	}
+	return target, writeForgeCache(cached)
+}
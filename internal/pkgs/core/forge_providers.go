@@ -0,0 +1,264 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+
+	forgePageSize = 100
+	// forgeMaxPages stops runaway pagination against misbehaving servers
+	forgeMaxPages = 100
+)
+
+// defaultForgeURLs are the public API endpoints used when a forge has no url
+var defaultForgeURLs = map[string]string{
+	ForgeGitHub: "https://api.github.com",
+	ForgeGitLab: "https://gitlab.com/api/v4",
+	ForgeGitea:  "https://gitea.com/api/v1",
+}
+
+var forgeHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// errForgeNotFound is returned for 404s, so providers can fall back from org to user endpoints
+var errForgeNotFound = errors.New("not found")
+
+// Provider lists the repositories of a user or organisation on a forge
+type Provider interface {
+	ListRepos(ctx context.Context, owner string) ([]RemoteRepo, error)
+}
+
+// NewProvider returns the provider for a forge type talking to baseURL, or the type's public API when empty
+func NewProvider(forgeType, baseURL, token string) (Provider, error) {
+	if baseURL == "" {
+		baseURL = defaultForgeURLs[forgeType]
+	}
+	api := forgeAPI{baseURL: strings.TrimRight(baseURL, "/"), token: token}
+
+	switch forgeType {
+	case ForgeGitHub:
+		return githubProvider{api}, nil
+	case ForgeGitLab:
+		return gitlabProvider{api}, nil
+	case ForgeGitea:
+		return giteaProvider{api}, nil
+	}
+	return nil, fmt.Errorf("unknown forge type '%s', expected %s, %s or %s", forgeType, ForgeGitHub, ForgeGitLab, ForgeGitea)
+}
+
+type forgeAPI struct {
+	baseURL string
+	token   string
+}
+
+// getJSON fetches baseURL+path and decodes the JSON response into out
+func (a forgeAPI) getJSON(ctx context.Context, path string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := forgeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, errForgeNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// listPages calls fetch for consecutive pages until one comes back short
+func listPages[T any](fetch func(page int) ([]T, error)) ([]T, error) {
+	var all []T
+	for page := 1; page <= forgeMaxPages; page++ {
+		items, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < forgePageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// githubRepo is the part of GitHub's and Gitea's repository objects in use
+type githubRepo struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	CloneURL    string `json:"clone_url"`
+	SSHURL      string `json:"ssh_url"`
+	HTMLURL     string `json:"html_url"`
+	Description string `json:"description"`
+	Archived    bool   `json:"archived"`
+	Fork        bool   `json:"fork"`
+}
+
+func (r githubRepo) remote() RemoteRepo {
+	return RemoteRepo{
+		Name:        r.Name,
+		FullName:    r.FullName,
+		CloneURL:    r.CloneURL,
+		SSHURL:      r.SSHURL,
+		WebURL:      r.HTMLURL,
+		Description: r.Description,
+		Archived:    r.Archived,
+		Fork:        r.Fork,
+	}
+}
+
+type githubProvider struct{ forgeAPI }
+
+func (p githubProvider) ListRepos(ctx context.Context, owner string) ([]RemoteRepo, error) {
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	list := func(endpoint, query string) ([]githubRepo, error) {
+		return listPages(func(page int) ([]githubRepo, error) {
+			var repos []githubRepo
+			path := fmt.Sprintf("%s?%sper_page=%d&page=%d", endpoint, query, forgePageSize, page)
+			err := p.getJSON(ctx, path, header.Clone(), &repos)
+			return repos, err
+		})
+	}
+
+	// organisation listings include the private repos the token can see, user listings never do
+	repos, err := list(fmt.Sprintf("/orgs/%s/repos", url.PathEscape(owner)), "")
+	if errors.Is(err, errForgeNotFound) {
+		if p.token != "" && strings.EqualFold(p.login(ctx, header), owner) {
+			repos, err = list("/user/repos", "affiliation=owner&")
+		} else {
+			repos, err = list(fmt.Sprintf("/users/%s/repos", url.PathEscape(owner)), "")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]RemoteRepo, len(repos))
+	for i, repo := range repos {
+		remotes[i] = repo.remote()
+	}
+	return remotes, nil
+}
+
+// login returns the user the token belongs to, or "" when it cannot be looked up
+func (p githubProvider) login(ctx context.Context, header http.Header) string {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := p.getJSON(ctx, "/user", header.Clone(), &user); err != nil {
+		return ""
+	}
+	return user.Login
+}
+
+type giteaProvider struct{ forgeAPI }
+
+func (p giteaProvider) ListRepos(ctx context.Context, owner string) ([]RemoteRepo, error) {
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("Authorization", "token "+p.token)
+	}
+
+	list := func(kind string) ([]githubRepo, error) {
+		return listPages(func(page int) ([]githubRepo, error) {
+			var repos []githubRepo
+			path := fmt.Sprintf("/%s/%s/repos?limit=%d&page=%d", kind, url.PathEscape(owner), forgePageSize, page)
+			err := p.getJSON(ctx, path, header.Clone(), &repos)
+			return repos, err
+		})
+	}
+
+	repos, err := list("orgs")
+	if errors.Is(err, errForgeNotFound) {
+		repos, err = list("users")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]RemoteRepo, len(repos))
+	for i, repo := range repos {
+		remotes[i] = repo.remote()
+	}
+	return remotes, nil
+}
+
+type gitlabProject struct {
+	Path              string `json:"path"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	HTTPURLToRepo     string `json:"http_url_to_repo"`
+	SSHURLToRepo      string `json:"ssh_url_to_repo"`
+	WebURL            string `json:"web_url"`
+	Description       string `json:"description"`
+	Archived          bool   `json:"archived"`
+	ForkedFrom        *struct {
+		ID int `json:"id"`
+	} `json:"forked_from_project"`
+}
+
+type gitlabProvider struct{ forgeAPI }
+
+func (p gitlabProvider) ListRepos(ctx context.Context, owner string) ([]RemoteRepo, error) {
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("PRIVATE-TOKEN", p.token)
+	}
+
+	list := func(endpoint string) ([]gitlabProject, error) {
+		return listPages(func(page int) ([]gitlabProject, error) {
+			var projects []gitlabProject
+			path := fmt.Sprintf("%s?include_subgroups=true&per_page=%d&page=%d", endpoint, forgePageSize, page)
+			err := p.getJSON(ctx, path, header.Clone(), &projects)
+			return projects, err
+		})
+	}
+
+	// owners are groups, possibly nested, or users
+	projects, err := list(fmt.Sprintf("/groups/%s/projects", url.PathEscape(owner)))
+	if errors.Is(err, errForgeNotFound) {
+		projects, err = list(fmt.Sprintf("/users/%s/projects", url.PathEscape(owner)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]RemoteRepo, len(projects))
+	for i, project := range projects {
+		remotes[i] = RemoteRepo{
+			Name:        project.Path,
+			FullName:    project.PathWithNamespace,
+			CloneURL:    project.HTTPURLToRepo,
+			SSHURL:      project.SSHURLToRepo,
+			WebURL:      project.WebURL,
+			Description: project.Description,
+			Archived:    project.Archived,
+			Fork:        project.ForkedFrom != nil,
+		}
+	}
+	return remotes, nil
+}
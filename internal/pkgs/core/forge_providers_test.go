@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedHandler serves items a page at a time, reading the page size from sizeParam
+func pagedHandler(t *testing.T, items []map[string]any, sizeParam string, check func(r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get(sizeParam))
+		if page < 1 || size != forgePageSize {
+			t.Errorf("unexpected paging %s", r.URL.RawQuery)
+		}
+		start := min((page-1)*size, len(items))
+		end := min(start+size, len(items))
+		if err := json.NewEncoder(w).Encode(items[start:end]); err != nil {
+			t.Errorf("failed to encode page: %v", err)
+		}
+	}
+}
+
+func githubItems(owner string, n int) []map[string]any {
+	items := make([]map[string]any, n)
+	for i := range items {
+		name := fmt.Sprintf("repo%d", i)
+		items[i] = map[string]any{
+			"name":      name,
+			"full_name": owner + "/" + name,
+			"clone_url": "https://example.com/" + owner + "/" + name + ".git",
+			"ssh_url":   "git@example.com:" + owner + "/" + name + ".git",
+			"html_url":  "https://example.com/" + owner + "/" + name,
+			"archived":  i == 0,
+			"fork":      i == 1,
+		}
+	}
+	return items
+}
+
+func TestGitHubProvider(t *testing.T) {
+	checkAuth := func(r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q, want bearer token", auth)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		checkAuth(r)
+		_, _ = w.Write([]byte(`{"login": "KahnWong"}`))
+	})
+	// the token's own user lists private repos through /user/repos
+	mux.HandleFunc("/user/repos", pagedHandler(t, githubItems("kahnwong", forgePageSize+5), "per_page", func(r *http.Request) {
+		checkAuth(r)
+		if affiliation := r.URL.Query().Get("affiliation"); affiliation != "owner" {
+			t.Errorf("affiliation = %q, want owner", affiliation)
+		}
+	}))
+	mux.HandleFunc("/orgs/infra/repos", pagedHandler(t, githubItems("infra", 2), "per_page", checkAuth))
+	mux.HandleFunc("/users/someone/repos", pagedHandler(t, githubItems("someone", 3), "per_page", checkAuth))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := NewProvider(ForgeGitHub, server.URL+"/", "secret")
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	repos, err := provider.ListRepos(context.Background(), "kahnwong")
+	if err != nil {
+		t.Fatalf("ListRepos() error = %v", err)
+	}
+
+	if len(repos) != forgePageSize+5 {
+		t.Fatalf("ListRepos() returned %d repos, want %d across pages", len(repos), forgePageSize+5)
+	}
+	first := repos[0]
+	if first.FullName != "kahnwong/repo0" || first.CloneURL != "https://example.com/kahnwong/repo0.git" || !first.Archived {
+		t.Errorf("ListRepos()[0] = %+v", first)
+	}
+	if !repos[1].Fork {
+		t.Errorf("ListRepos()[1].Fork = false, want true")
+	}
+
+	for owner, expected := range map[string]int{"infra": 2, "someone": 3} {
+		repos, err := provider.ListRepos(context.Background(), owner)
+		if err != nil || len(repos) != expected {
+			t.Errorf("ListRepos(%s) = %d repos, %v, want %d", owner, len(repos), err, expected)
+		}
+	}
+
+	if _, err := provider.ListRepos(context.Background(), "nobody"); err == nil {
+		t.Error("ListRepos() for unknown owner should fail")
+	}
+}
+
+func TestGiteaProviderFallsBackToUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/alice/repos", http.NotFound)
+	mux.HandleFunc("/users/alice/repos", pagedHandler(t, githubItems("alice", 3), "limit", func(r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "token secret" {
+			t.Errorf("Authorization = %q, want gitea token", auth)
+		}
+	}))
+	mux.HandleFunc("/orgs/infra/repos", pagedHandler(t, githubItems("infra", 2), "limit", nil))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, _ := NewProvider(ForgeGitea, server.URL, "secret")
+	for owner, expected := range map[string]int{"alice": 3, "infra": 2} {
+		repos, err := provider.ListRepos(context.Background(), owner)
+		if err != nil || len(repos) != expected {
+			t.Errorf("ListRepos(%s) = %d repos, %v, want %d", owner, len(repos), err, expected)
+		}
+	}
+}
+
+func TestGitLabProvider(t *testing.T) {
+	project := func(namespace, path string, fork bool) map[string]any {
+		item := map[string]any{
+			"path":                path,
+			"path_with_namespace": namespace + "/" + path,
+			"http_url_to_repo":    "https://gitlab.example.com/" + namespace + "/" + path + ".git",
+			"ssh_url_to_repo":     "git@gitlab.example.com:" + namespace + "/" + path + ".git",
+			"web_url":             "https://gitlab.example.com/" + namespace + "/" + path,
+			"description":         "about " + path,
+		}
+		if fork {
+			item["forked_from_project"] = map[string]any{"id": 1}
+		}
+		return item
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/groups/platform/projects", pagedHandler(t, []map[string]any{
+		project("platform", "api", false),
+		project("platform/tools", "ci", true),
+	}, "per_page", func(r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want token", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		if r.URL.Query().Get("include_subgroups") != "true" {
+			t.Error("group listing should include subgroups")
+		}
+	}))
+	mux.HandleFunc("/groups/bob/projects", http.NotFound)
+	mux.HandleFunc("/users/bob/projects", pagedHandler(t, []map[string]any{project("bob", "dotfiles", false)}, "per_page", nil))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, _ := NewProvider(ForgeGitLab, server.URL, "secret")
+	repos, err := provider.ListRepos(context.Background(), "platform")
+	if err != nil {
+		t.Fatalf("ListRepos() error = %v", err)
+	}
+	if len(repos) != 2 || repos[1].FullName != "platform/tools/ci" || repos[1].Name != "ci" || !repos[1].Fork || repos[0].Description != "about api" {
+		t.Errorf("ListRepos(platform) = %+v", repos)
+	}
+
+	repos, err = provider.ListRepos(context.Background(), "bob")
+	if err != nil || len(repos) != 1 || repos[0].FullName != "bob/dotfiles" {
+		t.Errorf("ListRepos(bob) = %+v, %v, want user projects", repos, err)
+	}
+}
+
+func TestNewProviderUnknownType(t *testing.T) {
+	if _, err := NewProvider("bitbucket", "", ""); err == nil {
+		t.Error("NewProvider(bitbucket) should fail")
+	}
+}
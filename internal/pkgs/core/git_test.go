@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitTestEnv isolates test git commands from the user's git config
+var gitTestEnv = []string{
+	"GIT_AUTHOR_NAME=test",
+	"GIT_AUTHOR_EMAIL=test@example.com",
+	"GIT_COMMITTER_NAME=test",
+	"GIT_COMMITTER_EMAIL=test@example.com",
+	"GIT_CONFIG_NOSYSTEM=1",
+	"GIT_CONFIG_GLOBAL=/dev/null",
+}
+
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+}
+
+// gitCmd runs git inside dir and fails the test on error
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), gitTestEnv...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitFile writes a file and commits it
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	gitCmd(t, dir, "add", name)
+	gitCmd(t, dir, "commit", "-q", "-m", "update "+name)
+}
+
+// newRemoteAndClone creates a bare remote with one commit and returns the remote and a fresh clone
+func newRemoteAndClone(t *testing.T) (remote, clone string) {
+	t.Helper()
+	requireGit(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	tempDir := t.TempDir()
+	remote = filepath.Join(tempDir, "remote.git")
+	gitCmd(t, tempDir, "init", "-q", "--bare", "-b", "main", remote)
+
+	seed := filepath.Join(tempDir, "seed")
+	gitCmd(t, tempDir, "clone", "-q", remote, seed)
+	commitFile(t, seed, "README.md", "seed\n")
+	gitCmd(t, seed, "push", "-q", "origin", "HEAD:main")
+
+	clone = cloneRemote(t, remote)
+	return remote, clone
+}
+
+// cloneRemote clones remote into a new temp directory
+func cloneRemote(t *testing.T, remote string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "clone")
+	gitCmd(t, filepath.Dir(dir), "clone", "-q", remote, dir)
+	return dir
+}
+
+func TestRunGit(t *testing.T) {
+	_, clone := newRemoteAndClone(t)
+
+	branch, err := runGit(context.Background(), clone, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		t.Fatalf("runGit() error = %v", err)
+	}
+	if branch != "main" {
+		t.Errorf("runGit() = %q, want %q", branch, "main")
+	}
+
+	if _, err := runGit(context.Background(), clone, "not-a-command"); err == nil {
+		t.Error("runGit() expected error for invalid command, got nil")
+	}
+}
+
+func TestAheadBehind(t *testing.T) {
+	remote, clone := newRemoteAndClone(t)
+
+	other := cloneRemote(t, remote)
+	commitFile(t, other, "other.txt", "other\n")
+	gitCmd(t, other, "push", "-q")
+
+	commitFile(t, clone, "local.txt", "local\n")
+	gitCmd(t, clone, "fetch", "-q")
+
+	ahead, behind, err := aheadBehind(context.Background(), clone)
+	if err != nil {
+		t.Fatalf("aheadBehind() error = %v", err)
+	}
+	if ahead != 1 || behind != 1 {
+		t.Errorf("aheadBehind() = %d, %d, want 1, 1", ahead, behind)
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	historyFileName = "history.json"
+	// maxHistory bounds the history log, dropping the oldest switches first
+	maxHistory = 100
+)
+
+var historyFilePath string
+
+var ErrNoHistory = errors.New("not enough history")
+
+type HistoryEntry struct {
+	Name string    `json:"name"`
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+}
+
+// readHistoryFile returns the history, oldest first, treating a missing file as empty
+func readHistoryFile() ([]HistoryEntry, error) {
+	data, err := os.ReadFile(historyFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("corrupt history %s: %w", historyFilePath, err)
+	}
+	return entries, nil
+}
+
+// writeHistoryFile replaces the history atomically, so readers never see a partial file
+func writeHistoryFile(entries []HistoryEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(historyFilePath), historyFileName+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), historyFilePath)
+}
+
+// DisplayName returns the name a repo path is indexed under, or the path when it has none
+func DisplayName(path string) string {
+	for name, repoPath := range ReposMap {
+		if repoPath == path {
+			return name
+		}
+	}
+	return path
+}
+
+// RecordSwitch appends a switch to path to the history.
+// Writers serialise on a lock file, so shells switching at the same time don't drop each other's entries.
+func RecordSwitch(path string) error {
+	if err := os.MkdirAll(filepath.Dir(historyFilePath), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(historyFilePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := readHistoryFile()
+	if err != nil {
+		// start over rather than failing every switch on a corrupt file
+		entries = nil
+	}
+
+	entry := HistoryEntry{Name: DisplayName(path), Path: path, Time: time.Now()}
+	if n := len(entries); n > 0 && entries[n-1].Path == path {
+		entries[n-1] = entry
+	} else {
+		entries = append(entries, entry)
+	}
+	if len(entries) > maxHistory {
+		entries = entries[len(entries)-maxHistory:]
+	}
+
+	return writeHistoryFile(entries)
+}
+
+// History returns recent switches, most recent first
+func History() ([]HistoryEntry, error) {
+	entries, err := readHistoryFile()
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// HistoryBack returns the repo switched to n switches before the current one, so 1 is the previous repo
+func HistoryBack(n int) (HistoryEntry, error) {
+	entries, err := History()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if n < 1 || n >= len(entries) {
+		return HistoryEntry{}, fmt.Errorf("%w: -%d needs %d switches, have %d", ErrNoHistory, n, n+1, len(entries))
+	}
+	return entries[n], nil
+}
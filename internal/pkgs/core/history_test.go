@@ -0,0 +1,126 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func setupHistory(t *testing.T) {
+	t.Helper()
+	original := historyFilePath
+	t.Cleanup(func() { historyFilePath = original })
+	historyFilePath = filepath.Join(t.TempDir(), "state", historyFileName)
+}
+
+func historyPaths(t *testing.T) []string {
+	t.Helper()
+	entries, err := History()
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+	}
+	return paths
+}
+
+func TestRecordSwitch(t *testing.T) {
+	setupHistory(t)
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: "/src/a"}, {Path: "/src/b"}})
+
+	if entries, err := History(); err != nil || len(entries) != 0 {
+		t.Fatalf("History() without a file = %v, %v, want empty", entries, err)
+	}
+
+	for _, path := range []string{"/src/a", "/src/b", "/src/b", "/src/a"} {
+		if err := RecordSwitch(path); err != nil {
+			t.Fatalf("RecordSwitch(%s) error = %v", path, err)
+		}
+	}
+
+	paths := historyPaths(t)
+	expected := []string{"/src/a", "/src/b", "/src/a"}
+	if fmt.Sprint(paths) != fmt.Sprint(expected) {
+		t.Errorf("History() = %v, want %v with repeated switches collapsed", paths, expected)
+	}
+
+	entries, _ := History()
+	if entries[0].Name != "a" || entries[1].Name != "b" {
+		t.Errorf("History() names = %s, %s, want a, b", entries[0].Name, entries[1].Name)
+	}
+}
+
+func TestRecordSwitchBounded(t *testing.T) {
+	setupHistory(t)
+	setupNamedRepos(t, NamingBasename, nil)
+
+	for i := 0; i < maxHistory+10; i++ {
+		if err := RecordSwitch(fmt.Sprintf("/src/%d", i)); err != nil {
+			t.Fatalf("RecordSwitch() error = %v", err)
+		}
+	}
+
+	paths := historyPaths(t)
+	if len(paths) != maxHistory {
+		t.Fatalf("History() has %d entries, want %d", len(paths), maxHistory)
+	}
+	if paths[0] != fmt.Sprintf("/src/%d", maxHistory+9) || paths[maxHistory-1] != "/src/10" {
+		t.Errorf("History() kept %s..%s, want the newest entries", paths[0], paths[maxHistory-1])
+	}
+}
+
+func TestRecordSwitchConcurrent(t *testing.T) {
+	setupHistory(t)
+	setupNamedRepos(t, NamingBasename, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := RecordSwitch(fmt.Sprintf("/src/%d", i)); err != nil {
+				t.Errorf("RecordSwitch() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if paths := historyPaths(t); len(paths) != 20 {
+		t.Errorf("History() has %d entries after concurrent writes, want 20", len(paths))
+	}
+}
+
+func TestHistoryBack(t *testing.T) {
+	setupHistory(t)
+	setupNamedRepos(t, NamingBasename, nil)
+
+	for _, path := range []string{"/src/a", "/src/b", "/src/c"} {
+		if err := RecordSwitch(path); err != nil {
+			t.Fatalf("RecordSwitch() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{1, "/src/b"},
+		{2, "/src/a"},
+	}
+	for _, tt := range tests {
+		entry, err := HistoryBack(tt.n)
+		if err != nil || entry.Path != tt.expected {
+			t.Errorf("HistoryBack(%d) = %s, %v, want %s", tt.n, entry.Path, err, tt.expected)
+		}
+	}
+
+	for _, n := range []int{0, 3} {
+		if _, err := HistoryBack(n); !errors.Is(err, ErrNoHistory) {
+			t.Errorf("HistoryBack(%d) error = %v, want ErrNoHistory", n, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// HookPre runs before the path is handed to the shell; a failure cancels the switch
+	HookPre = "pre"
+	// HookPost runs after the path is printed; failures are only reported
+	HookPost = "post"
+
+	defaultHookTimeout = 10 * time.Second
+	// hookWaitDelay bounds how long output left open by a hook's background children is waited for
+	hookWaitDelay = time.Second
+)
+
+// Hook is a shell command run when a repo is selected. Root, Group and Repo narrow which repos it
+// runs for; all that are set must match, and a hook without any runs for every repo.
+type Hook struct {
+	When    string `yaml:"when,omitempty"`
+	Run     string `yaml:"run"`
+	Root    string `yaml:"root,omitempty"`
+	Group   string `yaml:"group,omitempty"`
+	Repo    string `yaml:"repo,omitempty"`
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// when returns the hook's phase, post by default
+func (h Hook) when() string {
+	if h.When == "" {
+		return HookPost
+	}
+	return h.When
+}
+
+// timeout returns the hook's timeout, assuming validateHooks has accepted it
+func (h Hook) timeout() time.Duration {
+	if d, err := time.ParseDuration(h.Timeout); err == nil {
+		return d
+	}
+	return defaultHookTimeout
+}
+
+// validateHooks rejects hooks with unknown phases, no command, bad timeouts or undefined groups
+func validateHooks(hooks []Hook, groups map[string][]string) error {
+	var errs []error
+	for i, hook := range hooks {
+		switch hook.when() {
+		case HookPre, HookPost:
+		default:
+			errs = append(errs, fmt.Errorf("hooks[%d]: invalid when '%s', expected %s or %s", i, hook.When, HookPre, HookPost))
+		}
+		if hook.Run == "" {
+			errs = append(errs, fmt.Errorf("hooks[%d]: run is empty", i))
+		}
+		if hook.Timeout != "" {
+			if d, err := time.ParseDuration(hook.Timeout); err != nil || d <= 0 {
+				errs = append(errs, fmt.Errorf("hooks[%d]: invalid timeout '%s'", i, hook.Timeout))
+			}
+		}
+		if _, ok := groups[hook.Group]; hook.Group != "" && !ok {
+			errs = append(errs, fmt.Errorf("hooks[%d]: group '%s' is not defined in config", i, hook.Group))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hookMatches checks the hook's root, group and repo scopes against a repo
+func hookMatches(hook Hook, repoPath, name string) bool {
+	if hook.Root != "" {
+		root, err := expandPath(hook.Root)
+		if err != nil || !containsPath(filepath.Clean(root), repoPath) {
+			return false
+		}
+	}
+	if hook.Group != "" && !inGroup(repoPath, AppConfig.Groups[hook.Group]) {
+		return false
+	}
+	if hook.Repo != "" && matchKey(hook.Repo) != matchKey(name) {
+		return false
+	}
+	return true
+}
+
+// RunHooks runs every configured hook for the phase that applies to the repo, in config order.
+// Hooks run in the repo with its path and name in the environment, and write their output to out
+// so it never mixes with the path printed for the shell.
+func RunHooks(ctx context.Context, when, repoPath string, out io.Writer) error {
+	if AppConfig == nil {
+		return nil
+	}
+	name := DisplayName(repoPath)
+
+	var errs []error
+	for _, hook := range AppConfig.Hooks {
+		if hook.when() != when || !hookMatches(hook, repoPath, name) {
+			continue
+		}
+
+		hookCtx, cancel := context.WithTimeout(ctx, hook.timeout())
+		cmd := exec.CommandContext(hookCtx, "sh", "-c", hook.Run)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(),
+			"REPO_SWITCHER_HOOK="+when,
+			"REPO_SWITCHER_REPO_NAME="+name,
+			"REPO_SWITCHER_REPO_PATH="+repoPath,
+		)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		cmd.WaitDelay = hookWaitDelay
+
+		err := cmd.Run()
+		if hookCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", hook.timeout())
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s hook '%s': %w", when, hook.Run, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateHooks(t *testing.T) {
+	groups := map[string][]string{"work": {"~/work/*"}}
+
+	valid := []Hook{
+		{Run: "git fetch"},
+		{When: HookPre, Run: "true", Group: "work", Timeout: "2s"},
+	}
+	if err := validateHooks(valid, groups); err != nil {
+		t.Errorf("validateHooks() error = %v", err)
+	}
+
+	invalid := []Hook{
+		{When: "during", Run: "true"},
+		{Run: ""},
+		{Run: "true", Timeout: "soon"},
+		{Run: "true", Timeout: "-1s"},
+		{Run: "true", Group: "play"},
+	}
+	for _, hook := range invalid {
+		if err := validateHooks([]Hook{hook}, groups); err == nil {
+			t.Errorf("validateHooks(%+v) should fail", hook)
+		}
+	}
+}
+
+func TestRunHooksScopes(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "work/api/.git", "play/game/.git")
+	repos, _ := listGitRepos([]string{root}, false)
+	setupNamedRepos(t, NamingBasename, repos)
+
+	AppConfig.Groups = map[string][]string{"work": {filepath.Join(root, "work", "*")}}
+	AppConfig.Hooks = []Hook{
+		{Run: "echo all"},
+		{Run: "echo root", Root: filepath.Join(root, "play")},
+		{Run: "echo group", Group: "work"},
+		{Run: "echo repo", Repo: "API"},
+		{Run: "echo group-and-repo", Group: "work", Repo: "game"},
+		{When: HookPre, Run: "echo pre"},
+	}
+	AppConfig.IgnoreCase = true
+
+	tests := []struct {
+		repo     string
+		when     string
+		expected string
+	}{
+		{"work/api", HookPost, "all\ngroup\nrepo\n"},
+		{"play/game", HookPost, "all\nroot\n"},
+		{"play/game", HookPre, "pre\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := RunHooks(context.Background(), tt.when, filepath.Join(root, tt.repo), &out); err != nil {
+			t.Fatalf("RunHooks(%s) error = %v", tt.repo, err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("RunHooks(%s, %s) output = %q, want %q", tt.repo, tt.when, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestRunHooksEnvironment(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api/.git")
+	repos, _ := listGitRepos([]string{root}, false)
+	setupNamedRepos(t, NamingBasename, repos)
+	AppConfig.Hooks = []Hook{{Run: `echo "$REPO_SWITCHER_HOOK $REPO_SWITCHER_REPO_NAME $REPO_SWITCHER_REPO_PATH $(pwd)"`}}
+
+	repoPath := filepath.Join(root, "api")
+	var out bytes.Buffer
+	if err := RunHooks(context.Background(), HookPost, repoPath, &out); err != nil {
+		t.Fatalf("RunHooks() error = %v", err)
+	}
+	if expected := "post api " + repoPath + " " + repoPath + "\n"; out.String() != expected {
+		t.Errorf("RunHooks() output = %q, want %q", out.String(), expected)
+	}
+}
+
+func TestRunHooksFailures(t *testing.T) {
+	repoPath := t.TempDir()
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: repoPath}})
+	AppConfig.Hooks = []Hook{
+		{Run: "exit 3"},
+		{Run: "sleep 5", Timeout: "100ms"},
+		{Run: "echo still runs"},
+	}
+
+	var out bytes.Buffer
+	start := time.Now()
+	err := RunHooks(context.Background(), HookPost, repoPath, &out)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("RunHooks() took %s, want the timeout to stop the hook", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("RunHooks() error = %v, want exit status and timeout", err)
+	}
+	if out.String() != "still runs\n" {
+		t.Errorf("RunHooks() output = %q, want later hooks to still run", out.String())
+	}
+}
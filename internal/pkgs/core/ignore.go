@@ -0,0 +1,210 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const ignoreFileName = ".repo-switcher-ignore"
+
+type ignoreRule struct {
+	pattern string
+	line    int
+	negate  bool
+	re      *regexp.Regexp
+}
+
+// ignoreFile holds the rules of one ignore file, which apply to paths below its directory
+type ignoreFile struct {
+	path  string
+	dir   string
+	rules []ignoreRule
+}
+
+// ignoreStack holds the ignore files from the root down to the directory being walked
+type ignoreStack []*ignoreFile
+
+// globToRegexp translates a gitignore glob into a regular expression over slash-separated paths
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case glob[i:] == "**":
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// parseIgnoreRule compiles one gitignore line, returning false for blank lines and comments
+func parseIgnoreRule(line string, lineNumber int) (ignoreRule, bool, error) {
+	rule := ignoreRule{pattern: line, line: lineNumber}
+
+	line = strings.TrimRight(line, " \t")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false, nil
+	}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	// only directories are ever matched, so a trailing slash changes nothing
+	line = strings.TrimSuffix(line, "/")
+
+	// patterns with a slash before the end are relative to the ignore file, others match at any depth
+	expr := globToRegexp(strings.TrimPrefix(line, "/"))
+	if !strings.Contains(line, "/") {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule, false, err
+	}
+	rule.re = re
+	return rule, true, nil
+}
+
+// readIgnoreFile parses the ignore file in dir, returning nil when there is none
+func readIgnoreFile(dir string) (*ignoreFile, error) {
+	path := filepath.Join(dir, ignoreFileName)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ignore := &ignoreFile{path: path, dir: dir}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		rule, ok, err := parseIgnoreRule(scanner.Text(), lineNumber)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		if ok {
+			ignore.rules = append(ignore.rules, rule)
+		}
+	}
+	return ignore, scanner.Err()
+}
+
+// match returns the rule deciding whether dir is ignored, later rules and deeper files taking precedence
+func (s ignoreStack) match(dir string) (*ignoreFile, *ignoreRule) {
+	var matchedFile *ignoreFile
+	var matchedRule *ignoreRule
+	for _, file := range s {
+		rel, err := filepath.Rel(file.dir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for i := range file.rules {
+			if file.rules[i].re.MatchString(rel) {
+				matchedFile, matchedRule = file, &file.rules[i]
+			}
+		}
+	}
+	return matchedFile, matchedRule
+}
+
+// ignored reports whether dir is excluded by the stack
+func (s ignoreStack) ignored(dir string) bool {
+	_, rule := s.match(dir)
+	return rule != nil && !rule.negate
+}
+
+// ExplainPath describes whether a directory would be indexed and, if not, why it is excluded
+func ExplainPath(dir string) (string, error) {
+	if AppConfig == nil {
+		return "", fmt.Errorf("config not loaded")
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, root := range resolveRoots(AppConfig.Paths) {
+		if root.Err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root.Path, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+
+		stack := ignoreStack{}
+		current := root.Path
+		segments := []string{}
+		if rel != "." {
+			segments = strings.Split(rel, string(os.PathSeparator))
+		}
+
+		for depth := 0; ; depth++ {
+			ignore, err := readIgnoreFile(current)
+			if err != nil {
+				return "", err
+			}
+			if ignore != nil {
+				stack = append(stack, ignore)
+			}
+			if depth == len(segments) {
+				break
+			}
+
+			current = filepath.Join(current, segments[depth])
+			if depth+1 > maxScanDepth {
+				return fmt.Sprintf("excluded: %s is deeper than %d levels below root %s", current, maxScanDepth, root.Path), nil
+			}
+			if info, err := os.Lstat(current); err == nil && info.Mode()&os.ModeSymlink != 0 && !AppConfig.FollowSymlinks {
+				return fmt.Sprintf("excluded: %s is a symlink and follow_symlinks is off", current), nil
+			}
+			if file, rule := stack.match(current); rule != nil && !rule.negate {
+				return fmt.Sprintf("excluded: %s matches %q at %s:%d", current, rule.pattern, file.path, rule.line), nil
+			}
+		}
+
+		if info, err := os.Stat(filepath.Join(dir, ".git")); err != nil || !info.IsDir() {
+			return fmt.Sprintf("not excluded, but %s is not a git repository", dir), nil
+		}
+		return fmt.Sprintf("included under root %s", root.Path), nil
+	}
+
+	return fmt.Sprintf("excluded: %s is not under any configured path", dir), nil
+}
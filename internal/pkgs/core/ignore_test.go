@@ -0,0 +1,175 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"archive", "archive", true},
+		{"archive", "a/b/archive", true},
+		{"archive/", "archive", true},
+		{"/archive", "archive", true},
+		{"/archive", "a/archive", false},
+		{"vendor/*", "vendor/x", true},
+		{"vendor/*", "a/vendor/x", false},
+		{"**/vendor", "a/b/vendor", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"old/**", "old/x/y", true},
+		{"tmp-?", "tmp-1", true},
+		{"tmp-?", "tmp-10", false},
+		{"[ab]-repo", "b-repo", true},
+		{"[!ab]-repo", "c-repo", true},
+		{"*.bak", "x.bak", true},
+		{"*.bak", "x.bak/y", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.path, func(t *testing.T) {
+			rule, ok, err := parseIgnoreRule(tt.pattern, 1)
+			if err != nil || !ok {
+				t.Fatalf("parseIgnoreRule() = %v, %v", ok, err)
+			}
+			if result := rule.re.MatchString(tt.path); result != tt.matches {
+				t.Errorf("rule %q matching %q = %v, want %v", tt.pattern, tt.path, result, tt.matches)
+			}
+		})
+	}
+
+	for _, line := range []string{"", "   ", "# comment"} {
+		if _, ok, _ := parseIgnoreRule(line, 1); ok {
+			t.Errorf("parseIgnoreRule(%q) should be skipped", line)
+		}
+	}
+}
+
+func TestIgnoreStack(t *testing.T) {
+	root := t.TempDir()
+	writeConfigFile(t, filepath.Join(root, ignoreFileName), "archive\n!archive/keep\n")
+	writeConfigFile(t, filepath.Join(root, "work", ignoreFileName), "# local\nscratch\n")
+
+	var stack ignoreStack
+	for _, dir := range []string{root, filepath.Join(root, "work")} {
+		ignore, err := readIgnoreFile(dir)
+		if err != nil {
+			t.Fatalf("readIgnoreFile() error = %v", err)
+		}
+		stack = append(stack, ignore)
+	}
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"archive", true},
+		{"work/archive", true},
+		{"archive/keep", false},
+		{"work/scratch", true},
+		{"scratch", false},
+		{"work/api", false},
+	}
+	for _, tt := range tests {
+		if result := stack.ignored(filepath.Join(root, tt.path)); result != tt.ignored {
+			t.Errorf("ignored(%s) = %v, want %v", tt.path, result, tt.ignored)
+		}
+	}
+
+	_, rule := stack.match(filepath.Join(root, "work", "scratch"))
+	if rule == nil || rule.line != 2 {
+		t.Errorf("match() rule = %+v, want line 2", rule)
+	}
+}
+
+func TestReadIgnoreFileMissing(t *testing.T) {
+	ignore, err := readIgnoreFile(t.TempDir())
+	if ignore != nil || err != nil {
+		t.Errorf("readIgnoreFile() = %v, %v, want nil, nil", ignore, err)
+	}
+}
+
+func TestListGitReposIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api/.git", "archive/old/.git", "work/scratch/tmp/.git", "work/web/.git")
+	writeConfigFile(t, filepath.Join(root, ignoreFileName), "archive/\n")
+	writeConfigFile(t, filepath.Join(root, "work", ignoreFileName), "scratch\n")
+
+	repos, report := listGitRepos([]string{root}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
+	}
+
+	expected := map[string]bool{
+		filepath.Join(root, "api"):      true,
+		filepath.Join(root, "work/web"): true,
+	}
+	if len(repos) != len(expected) {
+		t.Errorf("listGitRepos() = %v, want %d repos", repoPaths(repos), len(expected))
+	}
+	for _, repo := range repos {
+		if !expected[repo.Path] {
+			t.Errorf("listGitRepos() found ignored repo %s", repo.Path)
+		}
+	}
+}
+
+func TestExplainPath(t *testing.T) {
+	originalAppConfig := AppConfig
+	defer func() { AppConfig = originalAppConfig }()
+
+	root := t.TempDir()
+	mkdirs(t, root, "api/.git", "archive/old/.git", "plain", "a/b/c/d/deep/.git", "..scratch/.git")
+	writeConfigFile(t, filepath.Join(root, ignoreFileName), "# skip\narchive\n..scratch\n")
+	AppConfig = &Config{Paths: []string{root}}
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{filepath.Join(root, "api"), "included"},
+		{filepath.Join(root, "archive/old"), ignoreFileName + ":2"},
+		{filepath.Join(root, "plain"), "not a git repository"},
+		{filepath.Join(root, "a/b/c/d/deep"), "deeper than"},
+		{filepath.Join(root, "..scratch"), ignoreFileName + ":3"},
+		{"/somewhere/else", "not under any configured path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := ExplainPath(tt.path)
+			if err != nil {
+				t.Fatalf("ExplainPath() error = %v", err)
+			}
+			if !strings.Contains(result, tt.contains) {
+				t.Errorf("ExplainPath() = %q, want it to contain %q", result, tt.contains)
+			}
+		})
+	}
+}
+
+func TestExplainPathSymlink(t *testing.T) {
+	originalAppConfig := AppConfig
+	defer func() { AppConfig = originalAppConfig }()
+
+	root := t.TempDir()
+	mkdirs(t, root, "real/repo/.git")
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "link")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	AppConfig = &Config{Paths: []string{root}}
+
+	result, err := ExplainPath(filepath.Join(root, "link", "repo"))
+	if err != nil {
+		t.Fatalf("ExplainPath() error = %v", err)
+	}
+	if !strings.Contains(result, "follow_symlinks") {
+		t.Errorf("ExplainPath() = %q, want symlink explanation", result)
+	}
+}
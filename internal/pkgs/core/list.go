@@ -1,38 +1,214 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
-
-	cli_base "github.com/kahnwong/cli-base"
+	"syscall"
+	"time"
 )
 
-func listGitRepos(paths []string) ([]string, error) {
-	var repos []string
-	var err error
+type RootReport struct {
+	Root             string
+	Path             string
+	Repos            int
+	Duration         time.Duration
+	Err              error
+	PermissionDenied []string
+	SymlinkLoops     []string
+	Errors           []string
+}
+
+type ScanReport struct {
+	Roots []RootReport
+}
+
+// Failed reports whether any root could not be scanned completely
+func (r *ScanReport) Failed() bool {
+	for _, root := range r.Roots {
+		if root.Err != nil || len(root.PermissionDenied) > 0 || len(root.SymlinkLoops) > 0 || len(root.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Err summarises every root failure, or returns nil when the scan was complete
+func (r *ScanReport) Err() error {
+	var errs []error
+	for _, root := range r.Roots {
+		if root.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", root.Root, root.Err))
+		}
+		if n := len(root.PermissionDenied) + len(root.SymlinkLoops) + len(root.Errors); n > 0 {
+			errs = append(errs, fmt.Errorf("%s: %d unreadable directories", root.Root, n))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordWalkError files a walk error under the matching category of the root report
+func (r *RootReport) recordWalkError(path string, err error) {
+	switch {
+	case errors.Is(err, syscall.ELOOP):
+		r.SymlinkLoops = append(r.SymlinkLoops, path)
+	case errors.Is(err, fs.ErrPermission):
+		r.PermissionDenied = append(r.PermissionDenied, path)
+	default:
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", path, err))
+	}
+}
 
-	for _, path := range paths {
-		gitDir, _ := cli_base.ExpandHome(path)
+// maxScanDepth is the deepest relative directory level that is still searched for .git
+const maxScanDepth = 4
 
-		err = filepath.Walk(gitDir, func(path string, info os.FileInfo, err error) error {
+// scanner walks one root, optionally following symlinked directories
+type scanner struct {
+	followSymlinks bool
+	report         *RootReport
+	repos          []Repo
+	// ancestors holds the directories on the current walk path, to detect symlink loops
+	ancestors map[fileID]bool
+	// ignores holds the ignore files on the current walk path
+	ignores ignoreStack
+}
+
+// walk searches dir, which sits depth levels below the root, for git repos
+func (s *scanner) walk(dir string, depth int) {
+	if id, err := dirID(dir); err == nil {
+		s.ancestors[id] = true
+		defer delete(s.ancestors, id)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		s.report.recordWalkError(dir, err)
+		return
+	}
+
+	ignore, err := readIgnoreFile(dir)
+	if err != nil {
+		s.report.recordWalkError(filepath.Join(dir, ignoreFileName), err)
+	}
+	if ignore != nil {
+		s.ignores = append(s.ignores, ignore)
+		defer func() { s.ignores = s.ignores[:len(s.ignores)-1] }()
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if !s.followSymlinks {
+				continue
+			}
+			info, err := os.Stat(path)
 			if err != nil {
-				return nil
+				// dangling links are not an error, but links that never resolve are
+				if errors.Is(err, syscall.ELOOP) {
+					s.report.recordWalkError(path, err)
+				}
+				continue
 			}
-
-			relPath, _ := filepath.Rel(gitDir, path)
-			if strings.Count(relPath, string(os.PathSeparator)) > 3 {
-				return filepath.SkipDir
+			if !info.IsDir() {
+				continue
 			}
+			id, err := dirID(path)
+			if err == nil && s.ancestors[id] {
+				s.report.SymlinkLoops = append(s.report.SymlinkLoops, path)
+				continue
+			}
+			isDir = true
+		}
+
+		if !isDir || depth+1 > maxScanDepth {
+			continue
+		}
 
-			if info.IsDir() && info.Name() == ".git" {
-				repos = append(repos, filepath.Dir(path))
-				return filepath.SkipDir
+		if entry.Name() == ".git" {
+			repo := newRepo(dir, s.report.Path)
+			meta, err := readRepoMeta(dir)
+			if err != nil {
+				s.report.recordWalkError(filepath.Join(dir, metaFileName), err)
 			}
+			repo.Meta = meta
+			repo.Activity = readActivity(dir)
+			repo.Branch = readBranch(dir)
+			s.repos = append(s.repos, repo)
+			continue
+		}
+
+		if s.ignores.ignored(path) {
+			continue
+		}
+
+		s.walk(path, depth+1)
+	}
+}
+
+// newRepo records a repo by the path it was reached through, its canonical location and its root
+func newRepo(path, root string) Repo {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		realPath = path
+	}
+	return Repo{Path: path, RealPath: realPath, Root: root}
+}
+
+// scanRoot walks a single root and returns every git repo found
+func scanRoot(root resolvedRoot, followSymlinks bool) ([]Repo, RootReport) {
+	report := RootReport{Root: root.Pattern, Path: root.Path, Err: root.Err}
+	if root.Err != nil {
+		return nil, report
+	}
+	start := time.Now()
+	defer func() { report.Duration = time.Since(start) }()
 
-			return nil
-		})
+	if _, err := os.Stat(root.Path); err != nil {
+		report.Err = err
+		return nil, report
 	}
 
-	return repos, err
+	s := &scanner{followSymlinks: followSymlinks, report: &report, ancestors: make(map[fileID]bool)}
+	s.walk(root.Path, 0)
+
+	report.Repos = len(s.repos)
+	return s.repos, report
+}
+
+// scanRoots walks every resolved root, keeping only the first repo found for each real path
+func scanRoots(roots []resolvedRoot, followSymlinks bool) ([]Repo, *ScanReport) {
+	var repos []Repo
+	seen := make(map[string]bool)
+	report := &ScanReport{}
+
+	for _, root := range roots {
+		rootRepos, rootReport := scanRoot(root, followSymlinks)
+		for _, repo := range rootRepos {
+			if seen[repo.RealPath] {
+				continue
+			}
+			seen[repo.RealPath] = true
+			repos = append(repos, repo)
+		}
+		report.Roots = append(report.Roots, rootReport)
+	}
+
+	return repos, report
+}
+
+func listGitRepos(paths []string, followSymlinks bool) ([]Repo, *ScanReport) {
+	return scanRoots(resolveRoots(paths), followSymlinks)
+}
+
+// repoPaths returns the display path of every repo
+func repoPaths(repos []Repo) []string {
+	paths := make([]string, len(repos))
+	for i, repo := range repos {
+		paths[i] = repo.Path
+	}
+	return paths
 }
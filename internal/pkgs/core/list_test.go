@@ -1,8 +1,10 @@
 package core
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
 )
 
@@ -34,9 +36,9 @@ func TestListGitRepos(t *testing.T) {
 	}
 
 	// Test listing git repos
-	repos, err := listGitRepos([]string{tempDir})
-	if err != nil {
-		t.Fatalf("listGitRepos() error = %v", err)
+	repos, report := listGitRepos([]string{tempDir}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
 	}
 
 	// Expected repos (excluding the one that's too deep)
@@ -57,7 +59,7 @@ func TestListGitRepos(t *testing.T) {
 	for _, expected := range expectedRepos {
 		found := false
 		for _, repo := range repos {
-			if repo == expected {
+			if repo.Path == expected {
 				found = true
 				break
 			}
@@ -71,9 +73,9 @@ func TestListGitRepos(t *testing.T) {
 func TestListGitReposEmptyDirectory(t *testing.T) {
 	tempDir := t.TempDir()
 
-	repos, err := listGitRepos([]string{tempDir})
-	if err != nil {
-		t.Fatalf("listGitRepos() error = %v", err)
+	repos, report := listGitRepos([]string{tempDir}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
 	}
 
 	if len(repos) != 0 {
@@ -99,9 +101,9 @@ func TestListGitReposMultiplePaths(t *testing.T) {
 	}
 
 	// Test listing git repos from both paths
-	repos, err := listGitRepos([]string{tempDir1, tempDir2})
-	if err != nil {
-		t.Fatalf("listGitRepos() error = %v", err)
+	repos, report := listGitRepos([]string{tempDir1, tempDir2}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
 	}
 
 	if len(repos) != 2 {
@@ -117,7 +119,7 @@ func TestListGitReposMultiplePaths(t *testing.T) {
 	for _, expected := range expectedRepos {
 		found := false
 		for _, repo := range repos {
-			if repo == expected {
+			if repo.Path == expected {
 				found = true
 				break
 			}
@@ -132,19 +134,92 @@ func TestListGitReposNonExistentPath(t *testing.T) {
 	// Test with a path that doesn't exist
 	nonExistentPath := "/this/path/does/not/exist/hopefully"
 
-	repos, err := listGitRepos([]string{nonExistentPath})
+	repos, report := listGitRepos([]string{nonExistentPath}, false)
 
-	// The function should handle the error gracefully and return empty list
-	if err != nil {
-		t.Logf("listGitRepos() returned error for non-existent path: %v", err)
+	// The missing root should be reported instead of silently ignored
+	if len(report.Roots) != 1 {
+		t.Fatalf("listGitRepos() reported %d roots, want 1", len(report.Roots))
+	}
+	if !os.IsNotExist(report.Roots[0].Err) {
+		t.Errorf("listGitRepos() root error = %v, want not exist", report.Roots[0].Err)
+	}
+	if !report.Failed() || report.Err() == nil {
+		t.Error("listGitRepos() report should fail for missing root")
+	}
+
+	if len(repos) != 0 {
+		t.Errorf("listGitRepos() found %d repos for non-existent path, want 0", len(repos))
+	}
+}
+
+func TestListGitReposPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
 	}
 
-	// Should return empty list or handle gracefully
-	if repos == nil {
-		repos = []string{}
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "repo1/.git"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	locked := filepath.Join(tempDir, "locked")
+	if err := os.MkdirAll(filepath.Join(locked, "repo2/.git"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+	defer func() { _ = os.Chmod(locked, 0755) }()
+
+	repos, report := listGitRepos([]string{tempDir}, false)
+
+	if len(repos) != 1 {
+		t.Errorf("listGitRepos() found %d repos, want 1", len(repos))
+	}
+	if len(report.Roots[0].PermissionDenied) != 1 || report.Roots[0].PermissionDenied[0] != locked {
+		t.Errorf("listGitRepos() permission denied = %v, want [%s]", report.Roots[0].PermissionDenied, locked)
+	}
+	if !report.Failed() {
+		t.Error("listGitRepos() report should fail for unreadable directory")
+	}
+}
+
+func TestListGitReposSymlinkLoopRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	loop := filepath.Join(tempDir, "loop")
+	if err := os.Symlink(loop, loop); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	_, report := listGitRepos([]string{loop}, false)
+
+	if !errors.Is(report.Roots[0].Err, syscall.ELOOP) {
+		t.Errorf("listGitRepos() root error = %v, want symlink loop", report.Roots[0].Err)
+	}
+}
+
+func TestListGitReposReport(t *testing.T) {
+	tempDir1 := t.TempDir()
+	tempDir2 := t.TempDir()
+	for _, repo := range []string{"repo1/.git", "repo2/.git"} {
+		if err := os.MkdirAll(filepath.Join(tempDir1, repo), 0755); err != nil {
+			t.Fatalf("failed to create test directory: %v", err)
+		}
 	}
 
-	t.Logf("listGitRepos() found %d repos for non-existent path", len(repos))
+	_, report := listGitRepos([]string{tempDir1, tempDir2}, false)
+
+	if len(report.Roots) != 2 {
+		t.Fatalf("listGitRepos() reported %d roots, want 2", len(report.Roots))
+	}
+	if report.Roots[0].Repos != 2 || report.Roots[1].Repos != 0 {
+		t.Errorf("listGitRepos() repo counts = %d, %d, want 2, 0", report.Roots[0].Repos, report.Roots[1].Repos)
+	}
+	if report.Roots[0].Path != tempDir1 {
+		t.Errorf("listGitRepos() root path = %s, want %s", report.Roots[0].Path, tempDir1)
+	}
+	if report.Failed() {
+		t.Errorf("listGitRepos() report failed unexpectedly: %v", report.Err())
+	}
 }
 
 func TestListGitReposDepthLimit(t *testing.T) {
@@ -172,9 +247,9 @@ func TestListGitReposDepthLimit(t *testing.T) {
 		}
 	}
 
-	repos, err := listGitRepos([]string{tempDir})
-	if err != nil {
-		t.Fatalf("listGitRepos() error = %v", err)
+	repos, report := listGitRepos([]string{tempDir}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
 	}
 
 	// Count how many should be found
@@ -196,7 +271,7 @@ func TestListGitReposDepthLimit(t *testing.T) {
 			expectedPath := filepath.Join(tempDir, filepath.Dir(tc.path))
 			found := false
 			for _, repo := range repos {
-				if repo == expectedPath {
+				if repo.Path == expectedPath {
 					found = true
 					break
 				}
@@ -212,7 +287,7 @@ func TestListGitReposDepthLimit(t *testing.T) {
 		if !tc.shouldBeFound {
 			unexpectedPath := filepath.Join(tempDir, filepath.Dir(tc.path))
 			for _, repo := range repos {
-				if repo == unexpectedPath {
+				if repo.Path == unexpectedPath {
 					t.Errorf("listGitRepos() found repo that should be skipped due to depth: %s", tc.path)
 				}
 			}
@@ -234,9 +309,9 @@ func TestListGitReposNestedGitRepos(t *testing.T) {
 		t.Fatalf("failed to create test directory: %v", err)
 	}
 
-	repos, err := listGitRepos([]string{tempDir})
-	if err != nil {
-		t.Fatalf("listGitRepos() error = %v", err)
+	repos, report := listGitRepos([]string{tempDir}, false)
+	if report.Failed() {
+		t.Fatalf("listGitRepos() error = %v", report.Err())
 	}
 
 	// Should find both repos
@@ -252,7 +327,7 @@ func TestListGitReposNestedGitRepos(t *testing.T) {
 	for _, expected := range expectedRepos {
 		found := false
 		for _, repo := range repos {
-			if repo == expected {
+			if repo.Path == expected {
 				found = true
 				break
 			}
@@ -262,3 +337,83 @@ func TestListGitReposNestedGitRepos(t *testing.T) {
 		}
 	}
 }
+
+func TestListGitReposSymlinks(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	root := filepath.Join(tempDir, "root")
+	elsewhere := filepath.Join(tempDir, "elsewhere")
+
+	mkdirs(t, root, "local/.git")
+	mkdirs(t, elsewhere, "linked/.git")
+	if err := os.Symlink(elsewhere, filepath.Join(root, "link")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	t.Run("not followed by default", func(t *testing.T) {
+		repos, report := listGitRepos([]string{root}, false)
+		if report.Failed() {
+			t.Fatalf("listGitRepos() error = %v", report.Err())
+		}
+		if len(repos) != 1 || repos[0].Path != filepath.Join(root, "local") {
+			t.Errorf("listGitRepos() = %+v, want only local repo", repos)
+		}
+	})
+
+	t.Run("followed when enabled", func(t *testing.T) {
+		repos, report := listGitRepos([]string{root}, true)
+		if report.Failed() {
+			t.Fatalf("listGitRepos() error = %v", report.Err())
+		}
+		expected := Repo{
+			Path:     filepath.Join(root, "link", "linked"),
+			RealPath: filepath.Join(elsewhere, "linked"),
+			Root:     root,
+		}
+		if len(repos) != 2 || repos[0] != expected {
+			t.Errorf("listGitRepos() = %+v, want %+v first", repos, expected)
+		}
+	})
+}
+
+func TestListGitReposSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "a/repo/.git")
+	loop := filepath.Join(root, "a", "back")
+	if err := os.Symlink(root, loop); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	repos, report := listGitRepos([]string{root}, true)
+
+	if len(repos) != 1 {
+		t.Errorf("listGitRepos() found %d repos, want 1: %+v", len(repos), repos)
+	}
+	if len(report.Roots[0].SymlinkLoops) != 1 || report.Roots[0].SymlinkLoops[0] != loop {
+		t.Errorf("listGitRepos() symlink loops = %v, want [%s]", report.Roots[0].SymlinkLoops, loop)
+	}
+}
+
+func TestListGitReposDeduplicatesRealPaths(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "b-real/repo/.git")
+	if err := os.Symlink(filepath.Join(root, "b-real"), filepath.Join(root, "a-link")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	repos, _ := listGitRepos([]string{root}, true)
+	if len(repos) != 1 {
+		t.Fatalf("listGitRepos() found %d repos, want 1: %+v", len(repos), repos)
+	}
+	if repos[0].Path != filepath.Join(root, "a-link", "repo") {
+		t.Errorf("listGitRepos() display path = %s, want path through first link", repos[0].Path)
+	}
+
+	// overlapping roots are deduplicated too
+	repos, _ = listGitRepos([]string{root, filepath.Join(root, "b-real")}, false)
+	if len(repos) != 1 {
+		t.Errorf("listGitRepos() found %d repos for overlapping roots, want 1: %+v", len(repos), repos)
+	}
+}
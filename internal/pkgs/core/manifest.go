@@ -0,0 +1,265 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ManifestRepo struct {
+	Root          string            `yaml:"root" json:"root"`
+	Path          string            `yaml:"path" json:"path"`
+	Remotes       map[string]string `yaml:"remotes,omitempty" json:"remotes,omitempty"`
+	DefaultBranch string            `yaml:"default_branch,omitempty" json:"default_branch,omitempty"`
+}
+
+type Manifest struct {
+	Repos []ManifestRepo `yaml:"repos" json:"repos"`
+}
+
+type RestoreStatus string
+
+const (
+	RestoreCloned   RestoreStatus = "cloned"
+	RestorePresent  RestoreStatus = "present"
+	RestoreNoRemote RestoreStatus = "skipped (no remote)"
+	RestoreFailed   RestoreStatus = "failed"
+)
+
+type RestoreResult struct {
+	Repo   ManifestRepo
+	Target string
+	Status RestoreStatus
+	Err    error
+}
+
+// key identifies a repo across machines
+func (r ManifestRepo) key() string {
+	return r.Root + "/" + r.Path
+}
+
+// findRoot returns the configured root containing repoPath, as written in config, and the relative path under it.
+// Glob roots are cut at their first wildcard so the matched directories stay part of the relative path.
+// The most specific root wins when roots are nested.
+func findRoot(repoPath string, roots []string) (string, string, bool) {
+	var bestRoot, bestRel string
+	bestLen := -1
+	for _, pattern := range roots {
+		root := staticPrefix(pattern)
+		expanded, err := expandPath(root)
+		if err != nil {
+			continue
+		}
+		expanded = filepath.Clean(expanded)
+		rel, err := filepath.Rel(expanded, repoPath)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		if len(expanded) > bestLen {
+			bestRoot, bestRel, bestLen = root, filepath.ToSlash(rel), len(expanded)
+		}
+	}
+	return bestRoot, bestRel, bestLen >= 0
+}
+
+// listRemotes returns the fetch URL of every remote
+func listRemotes(ctx context.Context, dir string) (map[string]string, error) {
+	out, err := runGit(ctx, dir, "remote", "-v")
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[2] == "(fetch)" {
+			remotes[fields[0]] = fields[1]
+		}
+	}
+	return remotes, nil
+}
+
+// defaultBranch returns origin's default branch, falling back to the current branch
+func defaultBranch(ctx context.Context, dir string) string {
+	if ref, err := runGit(ctx, dir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(ref, "origin/")
+	}
+	if branch, err := runGit(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		return branch
+	}
+	return ""
+}
+
+// BuildManifest describes every indexed repo relative to its configured root
+func BuildManifest(ctx context.Context) (*Manifest, error) {
+	if AppConfig == nil {
+		return nil, fmt.Errorf("config not loaded")
+	}
+
+	manifest := &Manifest{Repos: []ManifestRepo{}}
+	// the full index, so repos whose name is shadowed by another are restored too
+	for _, repo := range indexedRepos {
+		repoPath := repo.Path
+		root, rel, ok := findRoot(repoPath, AppConfig.Paths)
+		if !ok {
+			continue
+		}
+
+		remotes, err := listRemotes(ctx, repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", repoPath, err)
+		}
+
+		manifest.Repos = append(manifest.Repos, ManifestRepo{
+			Root:          root,
+			Path:          rel,
+			Remotes:       remotes,
+			DefaultBranch: defaultBranch(ctx, repoPath),
+		})
+	}
+
+	sort.Slice(manifest.Repos, func(i, j int) bool {
+		return manifest.Repos[i].key() < manifest.Repos[j].key()
+	})
+	return manifest, nil
+}
+
+// isJSONPath decides the manifest format from the file extension, defaulting to YAML
+func isJSONPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// MarshalManifest encodes the manifest as JSON or YAML
+func MarshalManifest(manifest *Manifest, asJSON bool) ([]byte, error) {
+	if asJSON {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	return yaml.Marshal(manifest)
+}
+
+// ReadManifest reads a manifest file in JSON or YAML format
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if isJSONPath(path) {
+		err = json.Unmarshal(data, &manifest)
+	} else {
+		err = yaml.Unmarshal(data, &manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
+
+// WriteManifest writes the manifest to path, choosing the format from its extension
+func WriteManifest(manifest *Manifest, path string) error {
+	data, err := MarshalManifest(manifest, isJSONPath(path))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// restoreRepo clones a manifest entry into target unless it already exists
+func restoreRepo(ctx context.Context, repo ManifestRepo, target string) (RestoreStatus, error) {
+	if _, err := os.Stat(target); err == nil {
+		return RestorePresent, nil
+	}
+	if len(repo.Remotes) == 0 {
+		return RestoreNoRemote, nil
+	}
+
+	names := make([]string, 0, len(repo.Remotes))
+	for name := range repo.Remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// clone from origin when present so it stays the default remote
+	primary := names[0]
+	if _, ok := repo.Remotes["origin"]; ok {
+		primary = "origin"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return RestoreFailed, err
+	}
+	if _, err := runGit(ctx, filepath.Dir(target), "clone", "--quiet", "--origin", primary, repo.Remotes[primary], target); err != nil {
+		return RestoreFailed, err
+	}
+
+	for _, name := range names {
+		if name == primary {
+			continue
+		}
+		if _, err := runGit(ctx, target, "remote", "add", name, repo.Remotes[name]); err != nil {
+			return RestoreFailed, err
+		}
+	}
+
+	return RestoreCloned, nil
+}
+
+// RestoreManifest clones every manifest repo missing on this machine into the same layout
+func RestoreManifest(ctx context.Context, manifest *Manifest, jobs int) []RestoreResult {
+	results := make([]RestoreResult, len(manifest.Repos))
+
+	forEachParallel(ctx, len(manifest.Repos), jobs, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Repo = manifest.Repos[i]
+
+		root, err := expandPath(result.Repo.Root)
+		if err != nil {
+			result.Status, result.Err = RestoreFailed, err
+			return
+		}
+		// manifests may come from elsewhere, so paths must stay under their root
+		result.Target, err = joinWithin(root, result.Repo.Path)
+		if err != nil {
+			result.Target = result.Repo.Path
+			result.Status, result.Err = RestoreFailed, err
+			return
+		}
+
+		result.Status, result.Err = restoreRepo(ctx, result.Repo, result.Target)
+	})
+
+	return results
+}
+
+// DiffManifests returns the repos only present in local and only present in other
+func DiffManifests(local, other *Manifest) (onlyLocal, onlyOther []ManifestRepo) {
+	localKeys := make(map[string]bool)
+	for _, repo := range local.Repos {
+		localKeys[repo.key()] = true
+	}
+	otherKeys := make(map[string]bool)
+	for _, repo := range other.Repos {
+		otherKeys[repo.key()] = true
+		if !localKeys[repo.key()] {
+			onlyOther = append(onlyOther, repo)
+		}
+	}
+	for _, repo := range local.Repos {
+		if !otherKeys[repo.key()] {
+			onlyLocal = append(onlyLocal, repo)
+		}
+	}
+	return onlyLocal, onlyOther
+}
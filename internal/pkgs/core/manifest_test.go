@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindRoot(t *testing.T) {
+	roots := []string{"/src", "/src/work", "/other"}
+
+	tests := []struct {
+		name         string
+		repoPath     string
+		expectedRoot string
+		expectedRel  string
+		expectedOk   bool
+	}{
+		{"direct child", "/src/repo1", "/src", "repo1", true},
+		{"nested root wins", "/src/work/api", "/src/work", "api", true},
+		{"deep path", "/other/a/b/c", "/other", "a/b/c", true},
+		{"outside roots", "/elsewhere/repo", "", "", false},
+		{"root itself", "/src", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, rel, ok := findRoot(tt.repoPath, roots)
+			if root != tt.expectedRoot || rel != tt.expectedRel || ok != tt.expectedOk {
+				t.Errorf("findRoot() = %q, %q, %v, want %q, %q, %v", root, rel, ok, tt.expectedRoot, tt.expectedRel, tt.expectedOk)
+			}
+		})
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	remote, _ := newRemoteAndClone(t)
+	root := t.TempDir()
+	first, second := filepath.Join(root, "a", "utils"), filepath.Join(root, "b", "utils")
+	gitCmd(t, root, "clone", "-q", remote, first)
+	gitCmd(t, root, "clone", "-q", remote, second)
+
+	originalReposMap := ReposMap
+	originalIndexedRepos := indexedRepos
+	originalAppConfig := AppConfig
+	defer func() {
+		ReposMap = originalReposMap
+		indexedRepos = originalIndexedRepos
+		AppConfig = originalAppConfig
+	}()
+	// a/utils is shadowed by b/utils but still exported
+	indexedRepos = []Repo{{Path: first}, {Path: second}}
+	ReposMap = map[string]string{"utils": second}
+	AppConfig = &Config{Paths: []string{root}}
+
+	manifest, err := BuildManifest(context.Background())
+	if err != nil {
+		t.Fatalf("BuildManifest() error = %v", err)
+	}
+
+	expected := []ManifestRepo{
+		{Root: root, Path: "a/utils", Remotes: map[string]string{"origin": remote}, DefaultBranch: "main"},
+		{Root: root, Path: "b/utils", Remotes: map[string]string{"origin": remote}, DefaultBranch: "main"},
+	}
+	if !reflect.DeepEqual(manifest.Repos, expected) {
+		t.Errorf("BuildManifest() = %+v, want %+v", manifest.Repos, expected)
+	}
+}
+
+func TestWriteAndReadManifest(t *testing.T) {
+	manifest := &Manifest{Repos: []ManifestRepo{{
+		Root:          "~/Git",
+		Path:          "kahnwong/repo-switcher",
+		Remotes:       map[string]string{"origin": "git@github.com:kahnwong/repo-switcher.git"},
+		DefaultBranch: "main",
+	}}}
+
+	for _, name := range []string{"manifest.yaml", "manifest.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := WriteManifest(manifest, path); err != nil {
+				t.Fatalf("WriteManifest() error = %v", err)
+			}
+
+			result, err := ReadManifest(path)
+			if err != nil {
+				t.Fatalf("ReadManifest() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, manifest) {
+				t.Errorf("ReadManifest() = %+v, want %+v", result, manifest)
+			}
+		})
+	}
+}
+
+func TestRestoreManifest(t *testing.T) {
+	remote, clone := newRemoteAndClone(t)
+	root := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, "present"), 0755); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+
+	manifest := &Manifest{Repos: []ManifestRepo{
+		{Root: root, Path: "group/missing", Remotes: map[string]string{"origin": remote, "mirror": clone}},
+		{Root: root, Path: "present", Remotes: map[string]string{"origin": remote}},
+		{Root: root, Path: "local-only"},
+		{Root: filepath.Join(root, "inner"), Path: "../escaped", Remotes: map[string]string{"origin": remote}},
+		{Root: root, Path: "/tmp/absolute", Remotes: map[string]string{"origin": remote}},
+	}}
+
+	results := RestoreManifest(context.Background(), manifest, 2)
+
+	expected := []RestoreStatus{RestoreCloned, RestorePresent, RestoreNoRemote, RestoreFailed, RestoreFailed}
+	for i, r := range results {
+		if r.Status != expected[i] {
+			t.Errorf("RestoreManifest() %s = %s (err %v), want %s", r.Repo.Path, r.Status, r.Err, expected[i])
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "escaped")); !os.IsNotExist(err) {
+		t.Errorf("restore escaped its root: %v", err)
+	}
+
+	target := filepath.Join(root, "group", "missing")
+	remotes, err := listRemotes(context.Background(), target)
+	if err != nil {
+		t.Fatalf("listRemotes() error = %v", err)
+	}
+	if !reflect.DeepEqual(remotes, manifest.Repos[0].Remotes) {
+		t.Errorf("restored remotes = %v, want %v", remotes, manifest.Repos[0].Remotes)
+	}
+}
+
+func TestDiffManifests(t *testing.T) {
+	local := &Manifest{Repos: []ManifestRepo{
+		{Root: "~/Git", Path: "shared"},
+		{Root: "~/Git", Path: "laptop-only"},
+	}}
+	other := &Manifest{Repos: []ManifestRepo{
+		{Root: "~/Git", Path: "shared"},
+		{Root: "~/work", Path: "server-only"},
+	}}
+
+	onlyLocal, onlyOther := DiffManifests(local, other)
+
+	if len(onlyLocal) != 1 || onlyLocal[0].Path != "laptop-only" {
+		t.Errorf("DiffManifests() onlyLocal = %+v, want laptop-only", onlyLocal)
+	}
+	if len(onlyOther) != 1 || onlyOther[0].Path != "server-only" {
+		t.Errorf("DiffManifests() onlyOther = %+v, want server-only", onlyOther)
+	}
+}
+
+func TestFindRootGlob(t *testing.T) {
+	root, rel, ok := findRoot("/src/work/team/code/api", []string{"/src/work/*/code"})
+	if !ok || root != "/src/work" || rel != "team/code/api" {
+		t.Errorf("findRoot() = %q, %q, %v, want /src/work, team/code/api, true", root, rel, ok)
+	}
+}
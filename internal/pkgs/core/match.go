@@ -0,0 +1,80 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// NormalizeNFC composes accents so "é" typed either way matches (default)
+	NormalizeNFC = "nfc"
+	// NormalizeNFD decomposes accents, e.g. to match names created on macOS as typed
+	NormalizeNFD = "nfd"
+	// NormalizeNone compares names byte for byte
+	NormalizeNone = "none"
+)
+
+// nameSeparators are ignored when ignore_separators is set; "/" still splits relative names
+const nameSeparators = "-_. "
+
+// validateNormalize rejects unknown normalisation forms
+func validateNormalize(form string) error {
+	switch form {
+	case "", NormalizeNFC, NormalizeNFD, NormalizeNone:
+		return nil
+	}
+	return fmt.Errorf("invalid normalize '%s', expected %s, %s or %s", form, NormalizeNFC, NormalizeNFD, NormalizeNone)
+}
+
+// matchKey folds a name according to the configured matching rules, so equivalent names share a key
+func matchKey(name string) string {
+	form, ignoreCase, ignoreSeparators := NormalizeNFC, false, false
+	if AppConfig != nil {
+		if AppConfig.Normalize != "" {
+			form = AppConfig.Normalize
+		}
+		ignoreCase, ignoreSeparators = AppConfig.IgnoreCase, AppConfig.IgnoreSeparators
+	}
+
+	switch form {
+	case NormalizeNFC:
+		name = norm.NFC.String(name)
+	case NormalizeNFD:
+		name = norm.NFD.String(name)
+	}
+	if ignoreCase {
+		name = cases.Fold().String(name)
+	}
+	if ignoreSeparators {
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(nameSeparators, r) {
+				return -1
+			}
+			return r
+		}, name)
+	}
+	return name
+}
+
+// nameCollisions groups distinct names that share a match key, so none of them resolves unless typed exactly
+func nameCollisions(names []string) [][]string {
+	byKey := make(map[string][]string)
+	for _, name := range names {
+		key := matchKey(name)
+		byKey[key] = append(byKey[key], name)
+	}
+
+	var collisions [][]string
+	for _, group := range byKey {
+		if len(group) > 1 {
+			sort.Strings(group)
+			collisions = append(collisions, group)
+		}
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i][0] < collisions[j][0] })
+	return collisions
+}
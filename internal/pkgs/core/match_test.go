@@ -0,0 +1,62 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchKey(t *testing.T) {
+	originalAppConfig := AppConfig
+	t.Cleanup(func() { AppConfig = originalAppConfig })
+
+	tests := []struct {
+		name     string
+		config   Config
+		a, b     string
+		expected bool
+	}{
+		{"exact by default", Config{}, "MyService", "myservice", false},
+		{"nfc by default", Config{}, "café", "café", true},
+		{"nfd", Config{Normalize: NormalizeNFD}, "café", "café", true},
+		{"no normalisation", Config{Normalize: NormalizeNone}, "café", "café", false},
+		{"ignore case", Config{IgnoreCase: true}, "MyService", "myservice", true},
+		{"ignore case folds ß", Config{IgnoreCase: true}, "STRASSE", "straße", true},
+		{"separators kept by default", Config{}, "foo_bar", "foo-bar", false},
+		{"ignore separators", Config{IgnoreSeparators: true}, "foo_bar", "foo-bar", true},
+		{"separators without case", Config{IgnoreSeparators: true}, "foo_bar", "FooBar", false},
+		{"separators and case", Config{IgnoreSeparators: true, IgnoreCase: true}, "foo_bar", "FooBar", true},
+		{"slash still splits", Config{IgnoreSeparators: true}, "a/bc", "ab/c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			AppConfig = &tt.config
+			if result := matchKey(tt.a) == matchKey(tt.b); result != tt.expected {
+				t.Errorf("matchKey(%q) == matchKey(%q) is %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateNormalize(t *testing.T) {
+	for _, form := range []string{"", NormalizeNFC, NormalizeNFD, NormalizeNone} {
+		if err := validateNormalize(form); err != nil {
+			t.Errorf("validateNormalize(%q) error = %v", form, err)
+		}
+	}
+	if err := validateNormalize("nfkc"); err == nil {
+		t.Error("validateNormalize(\"nfkc\") should fail")
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	originalAppConfig := AppConfig
+	t.Cleanup(func() { AppConfig = originalAppConfig })
+	AppConfig = &Config{IgnoreCase: true, IgnoreSeparators: true}
+
+	collisions := nameCollisions([]string{"foo-bar", "FooBar", "baz", "Foo_Bar", "qux", "QUX"})
+	expected := [][]string{{"FooBar", "Foo_Bar", "foo-bar"}, {"QUX", "qux"}}
+	if !reflect.DeepEqual(collisions, expected) {
+		t.Errorf("nameCollisions() = %v, want %v", collisions, expected)
+	}
+}
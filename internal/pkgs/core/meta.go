@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// metaFileName is the per-repo metadata file, read from the repo root while scanning
+const metaFileName = ".repo-switcher.yaml"
+
+// RepoMeta is what a repo declares about itself in its metadata file
+type RepoMeta struct {
+	// Name replaces the directory-derived display name
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Aliases resolve to the repo like its name, without being listed
+	Aliases     []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	// Open is run in the first window of a tmux session for the repo when its group has no layout
+	Open string `yaml:"open,omitempty" json:"open,omitempty"`
+}
+
+// readRepoMeta reads the metadata file in dir, returning nil when there is none
+func readRepoMeta(dir string) (*RepoMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var meta RepoMeta
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	meta.Name = strings.TrimSpace(meta.Name)
+	for _, name := range append([]string{meta.Name}, meta.Aliases...) {
+		if strings.HasPrefix(name, "-") {
+			return nil, fmt.Errorf("name '%s' would be read as a flag", name)
+		}
+	}
+	return &meta, nil
+}
+
+// indexedRepo returns the indexed repo at path, or nil
+func indexedRepo(path string) *Repo {
+	for i := range indexedRepos {
+		if indexedRepos[i].Path == path {
+			return &indexedRepos[i]
+		}
+	}
+	return nil
+}
+
+// buildAliases maps every declared alias to the display name of its repo; names always win over aliases
+func buildAliases(repos []Repo, reposMap map[string]string, mode string) map[string]string {
+	aliases := make(map[string]string)
+	for _, repo := range repos {
+		if repo.Meta == nil {
+			continue
+		}
+		name := repoName(repo, mode)
+		if reposMap[name] != repo.Path {
+			continue
+		}
+		for _, alias := range repo.Meta.Aliases {
+			if _, taken := reposMap[alias]; !taken && alias != "" {
+				aliases[alias] = name
+			}
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeMeta(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, metaFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", metaFileName, err)
+	}
+}
+
+func TestReadRepoMeta(t *testing.T) {
+	dir := t.TempDir()
+
+	meta, err := readRepoMeta(dir)
+	if err != nil || meta != nil {
+		t.Errorf("readRepoMeta() without file = %v, %v, want nil", meta, err)
+	}
+
+	writeMeta(t, dir, `
+name: " api "
+aliases: [backend, be]
+tags: [go, work]
+description: Public API
+open: nvim .
+`)
+	meta, err = readRepoMeta(dir)
+	if err != nil {
+		t.Fatalf("readRepoMeta() error = %v", err)
+	}
+	expected := &RepoMeta{Name: "api", Aliases: []string{"backend", "be"}, Tags: []string{"go", "work"}, Description: "Public API", Open: "nvim ."}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("readRepoMeta() = %+v, want %+v", meta, expected)
+	}
+
+	for _, content := range []string{"name: [", "aliases: [-b]", "name: -api"} {
+		writeMeta(t, dir, content)
+		if _, err := readRepoMeta(dir); err == nil {
+			t.Errorf("readRepoMeta(%q) should fail", content)
+		}
+	}
+}
+
+func TestListGitReposReadsMeta(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "api-server/.git", "web/.git", "broken/.git")
+	writeMeta(t, filepath.Join(root, "api-server"), "name: api\ntags: [go]\n")
+	writeMeta(t, filepath.Join(root, "broken"), "name: [")
+
+	repos, report := listGitRepos([]string{root}, false)
+	if len(repos) != 3 {
+		t.Fatalf("listGitRepos() found %d repos, want 3", len(repos))
+	}
+	for _, repo := range repos {
+		switch filepath.Base(repo.Path) {
+		case "api-server":
+			if repo.Meta == nil || repo.Meta.Name != "api" || !reflect.DeepEqual(repo.Meta.Tags, []string{"go"}) {
+				t.Errorf("api-server meta = %+v, want name api and tag go", repo.Meta)
+			}
+		default:
+			if repo.Meta != nil {
+				t.Errorf("%s meta = %+v, want nil", repo.Path, repo.Meta)
+			}
+		}
+	}
+	if len(report.Roots[0].Errors) != 1 {
+		t.Errorf("report errors = %v, want the broken metadata file", report.Roots[0].Errors)
+	}
+}
+
+func TestResolveRepoMeta(t *testing.T) {
+	setupNamedRepos(t, NamingRelative, []Repo{
+		{Path: "/src/work/api-server", Root: "/src", Meta: &RepoMeta{Name: "api", Aliases: []string{"Backend", "work/web"}}},
+		{Path: "/src/work/web", Root: "/src"},
+	})
+	AppConfig.IgnoreCase = true
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"api", "/src/work/api-server"},
+		{"Backend", "/src/work/api-server"},
+		{"backend", "/src/work/api-server"},
+		{"work/web", "/src/work/web"}, // names win over aliases
+	}
+	for _, tt := range tests {
+		path, err := ResolveRepo(tt.name)
+		if err != nil || path != tt.expected {
+			t.Errorf("ResolveRepo(%q) = %q, %v, want %q", tt.name, path, err, tt.expected)
+		}
+	}
+
+	if _, ok := ReposMap["work/api-server"]; ok {
+		t.Error("ReposMap still lists the directory name of a repo with a declared name")
+	}
+	if DisplayName("/src/work/api-server") != "api" {
+		t.Errorf("DisplayName() = %s, want api", DisplayName("/src/work/api-server"))
+	}
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// NamingBasename names each repo after its directory
+	NamingBasename = "basename"
+	// NamingRelative names each repo by its path relative to its root, e.g. kahnwong/repo-switcher
+	NamingRelative = "relative"
+)
+
+var ErrRepoNotFound = errors.New("repository not found")
+
+// AmbiguousError is returned when a name matches several repos, by basename or after folding
+type AmbiguousError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("'%s' is ambiguous: %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// naming returns the configured naming mode
+func naming() string {
+	if AppConfig == nil || AppConfig.Naming == "" {
+		return NamingBasename
+	}
+	return AppConfig.Naming
+}
+
+// validateNaming rejects unknown naming modes
+func validateNaming(mode string) error {
+	switch mode {
+	case "", NamingBasename, NamingRelative:
+		return nil
+	}
+	return fmt.Errorf("invalid naming '%s', expected %s or %s", mode, NamingBasename, NamingRelative)
+}
+
+// repoName returns the display name of a repo: its declared name, or one derived for the naming mode
+func repoName(repo Repo, mode string) string {
+	if repo.Meta != nil && repo.Meta.Name != "" {
+		return repo.Meta.Name
+	}
+	if mode == NamingRelative && repo.Root != "" {
+		if rel, err := filepath.Rel(repo.Root, repo.Path); err == nil && rel != "." {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(repo.Path)
+}
+
+// buildReposMap maps display names to repo paths, the last repo winning on collisions
+func buildReposMap(repos []Repo, mode string) map[string]string {
+	if mode != NamingRelative {
+		return createGitFolderMap(repos)
+	}
+
+	reposMap := make(map[string]string)
+	for _, repo := range repos {
+		reposMap[repoName(repo, mode)] = repo.Path
+	}
+	return reposMap
+}
+
+// setRepos rebuilds the name index from scanned or cached repos
+func setRepos(repos []Repo) {
+	indexedRepos = repos
+	ReposMap = buildReposMap(repos, naming())
+	ReposName = sortNames(getReposName(ReposMap), ReposMap, repos, sortStrategy())
+	repoAliases = buildAliases(repos, ReposMap, naming())
+}
+
+// matchingNames returns the sorted display names accepted by match
+func matchingNames(match func(name string) bool) []string {
+	var names []string
+	for name := range ReposMap {
+		if match(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// aliasTargets returns the sorted display names of repos with an alias matching key
+func aliasTargets(key string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for alias, name := range repoAliases {
+		if matchKey(alias) == key && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveRepo looks up a repo path by its display name or a declared alias, falling back to names that are equal under the
+// configured matching rules and, in relative naming mode, to an unambiguous basename
+func ResolveRepo(name string) (string, error) {
+	if path, ok := ReposMap[name]; ok {
+		return path, nil
+	}
+	if target, ok := repoAliases[name]; ok {
+		return ReposMap[target], nil
+	}
+
+	key := matchKey(name)
+	candidates := matchingNames(func(repoName string) bool { return matchKey(repoName) == key })
+	if len(candidates) == 0 {
+		candidates = aliasTargets(key)
+	}
+	if len(candidates) == 0 && naming() == NamingRelative && !strings.Contains(name, "/") {
+		candidates = matchingNames(func(repoName string) bool { return matchKey(path.Base(repoName)) == key })
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("%w: '%s'", ErrRepoNotFound, name)
+	case 1:
+		return ReposMap[candidates[0]], nil
+	default:
+		return "", &AmbiguousError{Name: name, Candidates: candidates}
+	}
+}
+
+// completeNames picks candidates for toComplete from names, keeping their order and appending the matching entry of
+// descriptions after a tab to candidates that are whole names. With relative names complete one path segment
+// at a time, like a filesystem path; noSpace is set when a candidate is a directory prefix to keep completing.
+func completeNames(names, descriptions []string, relative, keepOrder bool, toComplete string) (candidates []string, noSpace bool) {
+	describe := func(i int, candidate string) string {
+		if i < len(descriptions) && descriptions[i] != "" {
+			return candidate + "\t" + descriptions[i]
+		}
+		return candidate
+	}
+
+	if !relative {
+		if descriptions == nil {
+			return names, false
+		}
+		candidates = make([]string, len(names))
+		for i, name := range names {
+			candidates[i] = describe(i, name)
+		}
+		return candidates, false
+	}
+
+	seen := make(map[string]bool)
+	for i, name := range names {
+		if !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		candidate := name
+		if j := strings.Index(name[len(toComplete):], "/"); j >= 0 {
+			candidate = name[:len(toComplete)+j+1]
+			noSpace = true
+		}
+		if !seen[candidate] {
+			seen[candidate] = true
+			if candidate == name {
+				candidate = describe(i, name)
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	if !keepOrder {
+		sort.Strings(candidates)
+	}
+	return candidates, noSpace
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func setupNamedRepos(t *testing.T, mode string, repos []Repo) {
+	t.Helper()
+
+	originalAppConfig := AppConfig
+	originalReposMap := ReposMap
+	originalReposName := ReposName
+	t.Cleanup(func() {
+		AppConfig = originalAppConfig
+		ReposMap = originalReposMap
+		ReposName = originalReposName
+	})
+
+	AppConfig = &Config{Naming: mode}
+	setRepos(repos)
+}
+
+var namedTestRepos = []Repo{
+	{Path: "/src/kahnwong/repo-switcher", Root: "/src"},
+	{Path: "/src/work/infra/terraform", Root: "/src"},
+	{Path: "/src/work/api", Root: "/src"},
+	{Path: "/other/api", Root: "/other"},
+}
+
+func TestRepoName(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     Repo
+		mode     string
+		expected string
+	}{
+		{"basename", Repo{Path: "/src/a/b", Root: "/src"}, NamingBasename, "b"},
+		{"relative", Repo{Path: "/src/a/b", Root: "/src"}, NamingRelative, "a/b"},
+		{"relative without root", Repo{Path: "/src/a/b"}, NamingRelative, "b"},
+		{"relative repo is root", Repo{Path: "/src", Root: "/src"}, NamingRelative, "src"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := repoName(tt.repo, tt.mode); result != tt.expected {
+				t.Errorf("repoName() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateNaming(t *testing.T) {
+	for _, mode := range []string{"", NamingBasename, NamingRelative} {
+		if err := validateNaming(mode); err != nil {
+			t.Errorf("validateNaming(%q) error = %v", mode, err)
+		}
+	}
+	if err := validateNaming("fullpath"); err == nil {
+		t.Error("validateNaming() expected error for unknown mode, got nil")
+	}
+}
+
+func TestResolveRepoRelative(t *testing.T) {
+	setupNamedRepos(t, NamingRelative, namedTestRepos)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		notFound bool
+	}{
+		{name: "full relative name", input: "work/infra/terraform", expected: "/src/work/infra/terraform"},
+		{name: "unambiguous basename", input: "terraform", expected: "/src/work/infra/terraform"},
+		{name: "root level name", input: "api", expected: "/other/api"},
+		{name: "unknown", input: "missing", notFound: true},
+		{name: "partial path", input: "infra/terraform", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResolveRepo(tt.input)
+			if tt.notFound {
+				if !errors.Is(err, ErrRepoNotFound) {
+					t.Errorf("ResolveRepo() error = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("ResolveRepo() = %s, %v, want %s", result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRepoAmbiguous(t *testing.T) {
+	setupNamedRepos(t, NamingRelative, []Repo{
+		{Path: "/src/a/api", Root: "/src"},
+		{Path: "/src/b/api", Root: "/src"},
+	})
+
+	_, err := ResolveRepo("api")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("ResolveRepo() error = %v, want ambiguous", err)
+	}
+	if !reflect.DeepEqual(ambiguous.Candidates, []string{"a/api", "b/api"}) {
+		t.Errorf("ResolveRepo() candidates = %v, want [a/api b/api]", ambiguous.Candidates)
+	}
+}
+
+func TestResolveRepoBasename(t *testing.T) {
+	setupNamedRepos(t, NamingBasename, namedTestRepos)
+
+	if result, err := ResolveRepo("terraform"); err != nil || result != "/src/work/infra/terraform" {
+		t.Errorf("ResolveRepo() = %s, %v, want terraform path", result, err)
+	}
+	if _, err := ResolveRepo("work/api"); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("ResolveRepo() error = %v, want not found in basename mode", err)
+	}
+}
+
+func TestCompleteNamesRelative(t *testing.T) {
+	setupNamedRepos(t, NamingRelative, namedTestRepos)
+
+	tests := []struct {
+		toComplete string
+		expected   []string
+		noSpace    bool
+	}{
+		{"", []string{"api", "kahnwong/", "work/"}, true},
+		{"w", []string{"work/"}, true},
+		{"work/", []string{"work/api", "work/infra/"}, true},
+		{"work/infra/", []string{"work/infra/terraform"}, false},
+		{"kahnwong/repo", []string{"kahnwong/repo-switcher"}, false},
+		{"nope", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.toComplete, func(t *testing.T) {
+			result, noSpace := completeNames(ReposName, nil, true, false, tt.toComplete)
+			if !reflect.DeepEqual(result, tt.expected) || noSpace != tt.noSpace {
+				t.Errorf("completeNames(%q) = %v, %v, want %v, %v", tt.toComplete, result, noSpace, tt.expected, tt.noSpace)
+			}
+		})
+	}
+}
+
+func TestCompleteNamesBasename(t *testing.T) {
+	setupNamedRepos(t, NamingBasename, namedTestRepos)
+
+	result, noSpace := completeNames(ReposName, nil, false, false, "")
+	if len(result) != 3 || noSpace {
+		t.Errorf("completeNames() = %v, %v, want 3 basenames", result, noSpace)
+	}
+}
+
+func TestResolveRepoFolded(t *testing.T) {
+	setupNamedRepos(t, NamingRelative, []Repo{
+		{Path: "/src/work/My_Service", Root: "/src"},
+		{Path: "/src/work/café", Root: "/src"},
+		{Path: "/src/a/foo-bar", Root: "/src"},
+		{Path: "/src/b/foo_bar", Root: "/src"},
+	})
+	AppConfig.IgnoreCase = true
+	AppConfig.IgnoreSeparators = true
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"work/myservice", "/src/work/My_Service"},
+		{"myservice", "/src/work/My_Service"},
+		{"MY-SERVICE", "/src/work/My_Service"},
+		{"café", "/src/work/café"},
+		{"a/FooBar", "/src/a/foo-bar"},
+	}
+	for _, tt := range tests {
+		path, err := ResolveRepo(tt.name)
+		if err != nil || path != tt.expected {
+			t.Errorf("ResolveRepo(%q) = %q, %v, want %q", tt.name, path, err, tt.expected)
+		}
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := ResolveRepo("foobar"); !errors.As(err, &ambiguous) {
+		t.Fatalf("ResolveRepo(\"foobar\") error = %v, want AmbiguousError", err)
+	}
+	if expected := []string{"a/foo-bar", "b/foo_bar"}; !reflect.DeepEqual(ambiguous.Candidates, expected) {
+		t.Errorf("Candidates = %v, want %v", ambiguous.Candidates, expected)
+	}
+}
+
+func TestResolveRepoExactBeatsFolded(t *testing.T) {
+	setupNamedRepos(t, NamingBasename, []Repo{{Path: "/src/api"}, {Path: "/other/API"}})
+	AppConfig.IgnoreCase = true
+
+	if path, err := ResolveRepo("API"); err != nil || path != "/other/API" {
+		t.Errorf("ResolveRepo(\"API\") = %q, %v, want /other/API", path, err)
+	}
+
+	var ambiguous *AmbiguousError
+	if _, err := ResolveRepo("Api"); !errors.As(err, &ambiguous) {
+		t.Errorf("ResolveRepo(\"Api\") error = %v, want AmbiguousError", err)
+	}
+}
@@ -0,0 +1,298 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	cliBase "github.com/kahnwong/cli-base"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigOrigins maps each effective config value (e.g. "paths" or "groups.work") to the file it came from
+var ConfigOrigins map[string]string
+
+// hostMatches checks a hosts: key against the full and short hostname
+func hostMatches(pattern, hostname string) bool {
+	short, _, _ := strings.Cut(hostname, ".")
+	for _, name := range []string{hostname, short} {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlName returns the yaml key of a struct field
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// yamlValue returns the value of key in a YAML mapping, or nil
+func yamlValue(node *yaml.Node, key string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlKeys returns the keys written in a YAML mapping, so values explicitly set to false or "" are told apart from unset ones
+func yamlKeys(node *yaml.Node) map[string]bool {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	keys := make(map[string]bool)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return keys
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys[node.Content[i].Value] = true
+	}
+	return keys
+}
+
+// readConfigFile decodes a config file, also returning its YAML tree to see which keys it sets
+func readConfigFile(path string) (*Config, *yaml.Node, error) {
+	expanded, err := cliBase.CheckIfConfigExists(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(expanded)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, nil, err
+	}
+	config := &Config{}
+	if node.Kind != 0 {
+		if err := node.Decode(config); err != nil {
+			return nil, nil, err
+		}
+	}
+	return config, &node, nil
+}
+
+// mergeConfig applies every value written in overlay, as listed by keys, onto dst.
+// Map fields are merged key by key, all other fields are replaced as a whole, even by false or "".
+func mergeConfig(dst, overlay *Config, keys map[string]bool, origin string, origins map[string]string) {
+	dstValue := reflect.ValueOf(dst).Elem()
+	overlayValue := reflect.ValueOf(overlay).Elem()
+
+	for i := 0; i < dstValue.NumField(); i++ {
+		field := dstValue.Type().Field(i)
+		name := yamlName(field)
+		if name == "hosts" || name == "include" {
+			continue
+		}
+
+		value := overlayValue.Field(i)
+		if !keys[name] {
+			continue
+		}
+
+		if value.Kind() == reflect.Map {
+			target := dstValue.Field(i)
+			if target.IsNil() {
+				target.Set(reflect.MakeMap(value.Type()))
+			}
+			iter := value.MapRange()
+			for iter.Next() {
+				target.SetMapIndex(iter.Key(), iter.Value())
+				origins[fmt.Sprintf("%s.%v", name, iter.Key())] = origin
+			}
+			continue
+		}
+
+		dstValue.Field(i).Set(value)
+		origins[name] = origin
+	}
+}
+
+// hostSection is a hosts: entry of the config file or one of its includes
+type hostSection struct {
+	pattern string
+	config  *Config
+	keys    map[string]bool
+	file    string
+}
+
+// collectHosts appends the hosts: sections of a config file to hosts. Sections don't nest, so hosts is nil
+// for files included from a section and any hosts: there is an error rather than silently dropped.
+func collectHosts(config *Config, node *yaml.Node, file string, hosts *[]hostSection) error {
+	if len(config.Hosts) == 0 {
+		return nil
+	}
+	if hosts == nil {
+		return fmt.Errorf("%s: hosts: is not allowed in a file included from a hosts section", file)
+	}
+
+	patterns := make([]string, 0, len(config.Hosts))
+	for pattern := range config.Hosts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		overlay := config.Hosts[pattern]
+		if overlay == nil {
+			continue
+		}
+		if len(overlay.Hosts) > 0 {
+			return fmt.Errorf("%s: hosts.%s: hosts sections cannot be nested", file, pattern)
+		}
+		keys := yamlKeys(yamlValue(yamlValue(node, "hosts"), pattern))
+		*hosts = append(*hosts, hostSection{pattern: pattern, config: overlay, keys: keys, file: file})
+	}
+	return nil
+}
+
+// applyIncludes merges every included file into dst, resolving paths relative to the including file,
+// and collects their hosts: sections into hosts.
+// chain holds the files being included above this one, so a file may be included twice but not by itself.
+func applyIncludes(dst *Config, includes []string, baseDir string, origins map[string]string, chain map[string]bool, hosts *[]hostSection) error {
+	for _, include := range includes {
+		path, err := cliBase.ExpandHome(include)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		if chain[path] {
+			return fmt.Errorf("include cycle detected at %s", path)
+		}
+
+		included, node, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read include %s: %w", include, err)
+		}
+
+		mergeConfig(dst, included, yamlKeys(node), path, origins)
+		if err := collectHosts(included, node, path, hosts); err != nil {
+			return err
+		}
+		chain[path] = true
+		err = applyIncludes(dst, included.Include, filepath.Dir(path), origins, chain, hosts)
+		delete(chain, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfig reads the config at path and computes the effective config for hostname:
+// the base file, then its includes in order, then every matching hosts: section of any of them
+// followed by the files that section includes.
+func loadConfig(path string, hostname string) (*Config, map[string]string, error) {
+	base, node, err := readConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	origins := make(map[string]string)
+	config := &Config{}
+	mergeConfig(config, base, yamlKeys(node), path, origins)
+
+	var hosts []hostSection
+	if err := collectHosts(base, node, path, &hosts); err != nil {
+		return nil, nil, err
+	}
+	if err := applyIncludes(config, base.Include, filepath.Dir(path), origins, map[string]bool{path: true}, &hosts); err != nil {
+		return nil, nil, err
+	}
+
+	// apply glob patterns before exact hostnames so the most specific section wins,
+	// sections with the same pattern in file order
+	sort.SliceStable(hosts, func(i, j int) bool {
+		iExact, jExact := hosts[i].pattern == hostname, hosts[j].pattern == hostname
+		if iExact != jExact {
+			return jExact
+		}
+		return hosts[i].pattern < hosts[j].pattern
+	})
+
+	for _, section := range hosts {
+		if !hostMatches(section.pattern, hostname) {
+			continue
+		}
+		mergeConfig(config, section.config, section.keys, fmt.Sprintf("%s (hosts.%s)", section.file, section.pattern), origins)
+		chain := map[string]bool{path: true, section.file: true}
+		if err := applyIncludes(config, section.config.Include, filepath.Dir(section.file), origins, chain, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return config, origins, nil
+}
+
+// readConfig loads the effective config for the current machine
+func readConfig(path string) (*Config, map[string]string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	return loadConfig(path, hostname)
+}
+
+// EffectiveConfigYaml renders the merged config with the origin of each value as a comment
+func EffectiveConfigYaml() ([]byte, error) {
+	if AppConfig == nil {
+		return nil, fmt.Errorf("config not loaded")
+	}
+
+	var doc yaml.Node
+	if err := doc.Encode(AppConfig); err != nil {
+		return nil, err
+	}
+	annotateOrigins(&doc, "", ConfigOrigins)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// annotateOrigins adds the origin of each top-level value, and of each entry of map values, as line comments
+func annotateOrigins(node *yaml.Node, prefix string, origins map[string]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		name := key.Value
+		if prefix != "" {
+			name = prefix + "." + key.Value
+		}
+
+		if origin, ok := origins[name]; ok {
+			key.LineComment = "from " + origin
+		} else if prefix == "" {
+			annotateOrigins(value, name, origins)
+		}
+	}
+}
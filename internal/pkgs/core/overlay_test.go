@@ -0,0 +1,309 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestHostMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		hostname string
+		expected bool
+	}{
+		{"laptop", "laptop", true},
+		{"laptop", "laptop.local", true},
+		{"laptop-*", "laptop-work", true},
+		{"server-*", "laptop-work", false},
+		{"*.example.com", "build.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.hostname, func(t *testing.T) {
+			if result := hostMatches(tt.pattern, tt.hostname); result != tt.expected {
+				t.Errorf("hostMatches(%q, %q) = %v, want %v", tt.pattern, tt.hostname, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigHosts(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	writeConfigFile(t, configPath, `
+paths:
+  - ~/Git
+groups:
+  work:
+    - ~/Git/work/*
+hosts:
+  server-*:
+    paths:
+      - /srv/git
+  server-build:
+    groups:
+      ci:
+        - /srv/git/ci/*
+`)
+
+	tests := []struct {
+		name           string
+		hostname       string
+		expectedPaths  []string
+		expectedGroups []string
+		expectedOrigin string
+	}{
+		{
+			name:           "no matching host",
+			hostname:       "laptop",
+			expectedPaths:  []string{"~/Git"},
+			expectedGroups: []string{"work"},
+			expectedOrigin: configPath,
+		},
+		{
+			name:           "glob host",
+			hostname:       "server-web",
+			expectedPaths:  []string{"/srv/git"},
+			expectedGroups: []string{"work"},
+			expectedOrigin: configPath + " (hosts.server-*)",
+		},
+		{
+			name:           "glob and exact host",
+			hostname:       "server-build",
+			expectedPaths:  []string{"/srv/git"},
+			expectedGroups: []string{"ci", "work"},
+			expectedOrigin: configPath + " (hosts.server-*)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, origins, err := loadConfig(configPath, tt.hostname)
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(config.Paths, tt.expectedPaths) {
+				t.Errorf("loadConfig() paths = %v, want %v", config.Paths, tt.expectedPaths)
+			}
+			if len(config.Groups) != len(tt.expectedGroups) {
+				t.Errorf("loadConfig() groups = %v, want %v", config.Groups, tt.expectedGroups)
+			}
+			for _, group := range tt.expectedGroups {
+				if _, ok := config.Groups[group]; !ok {
+					t.Errorf("loadConfig() missing group %s", group)
+				}
+			}
+			if origins["paths"] != tt.expectedOrigin {
+				t.Errorf("loadConfig() paths origin = %q, want %q", origins["paths"], tt.expectedOrigin)
+			}
+			if config.Hosts != nil {
+				t.Error("loadConfig() effective config should not contain hosts")
+			}
+		})
+	}
+}
+
+func TestLoadConfigHostsResetToFalse(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, configPath, `
+paths:
+  - ~/Git
+follow_symlinks: true
+ignore_case: true
+hosts:
+  server:
+    follow_symlinks: false
+`)
+
+	config, origins, err := loadConfig(configPath, "server")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.FollowSymlinks {
+		t.Error("loadConfig() follow_symlinks = true, want the host's false")
+	}
+	if !config.IgnoreCase {
+		t.Error("loadConfig() ignore_case = false, want the base value kept")
+	}
+	if origin := origins["follow_symlinks"]; origin != configPath+" (hosts.server)" {
+		t.Errorf("loadConfig() follow_symlinks origin = %q", origin)
+	}
+}
+
+func TestLoadConfigInclude(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	localPath := filepath.Join(tempDir, "local", "paths.yaml")
+	writeConfigFile(t, configPath, `
+paths:
+  - ~/Git
+include:
+  - local/paths.yaml
+`)
+	writeConfigFile(t, localPath, `
+paths:
+  - /data/git
+groups:
+  data:
+    - /data/git/*
+`)
+
+	config, origins, err := loadConfig(configPath, "laptop")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(config.Paths, []string{"/data/git"}) {
+		t.Errorf("loadConfig() paths = %v, want [/data/git]", config.Paths)
+	}
+	if origins["paths"] != localPath {
+		t.Errorf("loadConfig() paths origin = %q, want %q", origins["paths"], localPath)
+	}
+	if origins["groups.data"] != localPath {
+		t.Errorf("loadConfig() groups.data origin = %q, want %q", origins["groups.data"], localPath)
+	}
+}
+
+func TestLoadConfigIncludeDiamond(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	writeConfigFile(t, configPath, "include:\n  - laptop.yaml\n  - work.yaml\n")
+	writeConfigFile(t, filepath.Join(tempDir, "laptop.yaml"), "include:\n  - shared.yaml\n")
+	writeConfigFile(t, filepath.Join(tempDir, "work.yaml"), "include:\n  - shared.yaml\n")
+	writeConfigFile(t, filepath.Join(tempDir, "shared.yaml"), "paths:\n  - ~/Git\n")
+
+	config, _, err := loadConfig(configPath, "laptop")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v, want a file shared by two includes accepted", err)
+	}
+	if !reflect.DeepEqual(config.Paths, []string{"~/Git"}) {
+		t.Errorf("loadConfig() paths = %v, want [~/Git]", config.Paths)
+	}
+}
+
+func TestLoadConfigHostsAndIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	sharedPath := filepath.Join(tempDir, "shared.yaml")
+	laptopPath := filepath.Join(tempDir, "hosts", "laptop.yaml")
+	writeConfigFile(t, configPath, `
+paths:
+  - ~/Git
+include:
+  - shared.yaml
+hosts:
+  laptop:
+    include:
+      - hosts/laptop.yaml
+`)
+	writeConfigFile(t, sharedPath, `
+hosts:
+  "*":
+    ignore_case: true
+`)
+	writeConfigFile(t, laptopPath, `
+groups:
+  personal:
+    - ~/Git/personal/*
+`)
+
+	config, origins, err := loadConfig(configPath, "laptop")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if !config.IgnoreCase || origins["ignore_case"] != sharedPath+" (hosts.*)" {
+		t.Errorf("loadConfig() ignore_case = %v from %q, want the included file's hosts section applied", config.IgnoreCase, origins["ignore_case"])
+	}
+	if _, ok := config.Groups["personal"]; !ok || origins["groups.personal"] != laptopPath {
+		t.Errorf("loadConfig() groups = %v from %q, want the host section's include applied", config.Groups, origins["groups.personal"])
+	}
+
+	config, _, err = loadConfig(configPath, "server")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if _, ok := config.Groups["personal"]; ok {
+		t.Error("loadConfig() applied the laptop include on another host")
+	}
+}
+
+func TestLoadConfigIncludeErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("missing include", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, "missing.yaml")
+		writeConfigFile(t, configPath, "include:\n  - nope.yaml\n")
+		if _, _, err := loadConfig(configPath, "laptop"); err == nil {
+			t.Error("loadConfig() expected error for missing include, got nil")
+		}
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, "a.yaml")
+		writeConfigFile(t, configPath, "include:\n  - b.yaml\n")
+		writeConfigFile(t, filepath.Join(tempDir, "b.yaml"), "include:\n  - a.yaml\n")
+		if _, _, err := loadConfig(configPath, "laptop"); err == nil {
+			t.Error("loadConfig() expected error for include cycle, got nil")
+		}
+	})
+
+	t.Run("nested hosts", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, "nested.yaml")
+		writeConfigFile(t, configPath, "hosts:\n  laptop:\n    hosts:\n      work:\n        naming: relative\n")
+		if _, _, err := loadConfig(configPath, "laptop"); err == nil {
+			t.Error("loadConfig() expected error for nested hosts, got nil")
+		}
+	})
+
+	t.Run("hosts in a host include", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, "host-include.yaml")
+		writeConfigFile(t, configPath, "hosts:\n  laptop:\n    include:\n      - host-hosts.yaml\n")
+		writeConfigFile(t, filepath.Join(tempDir, "host-hosts.yaml"), "hosts:\n  work:\n    naming: relative\n")
+		if _, _, err := loadConfig(configPath, "laptop"); err == nil {
+			t.Error("loadConfig() expected error for hosts in a file included from a hosts section, got nil")
+		}
+	})
+}
+
+func TestEffectiveConfigYaml(t *testing.T) {
+	originalAppConfig := AppConfig
+	originalOrigins := ConfigOrigins
+	defer func() {
+		AppConfig = originalAppConfig
+		ConfigOrigins = originalOrigins
+	}()
+
+	AppConfig = &Config{
+		Paths:  []string{"~/Git"},
+		Groups: map[string][]string{"work": {"~/Git/work/*"}},
+	}
+	ConfigOrigins = map[string]string{
+		"paths":       "config.yaml",
+		"groups.work": "local.yaml",
+	}
+
+	data, err := EffectiveConfigYaml()
+	if err != nil {
+		t.Fatalf("EffectiveConfigYaml() error = %v", err)
+	}
+
+	output := string(data)
+	for _, expected := range []string{"paths: # from config.yaml", "work: # from local.yaml"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("EffectiveConfigYaml() missing %q in:\n%s", expected, output)
+		}
+	}
+}
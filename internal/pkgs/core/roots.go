@@ -0,0 +1,187 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	cliBase "github.com/kahnwong/cli-base"
+)
+
+// resolvedRoot is a concrete directory to scan, produced from a configured path
+type resolvedRoot struct {
+	Pattern string
+	Path    string
+	Err     error
+}
+
+// expandPath expands ~ and $VAR / ${VAR} references, failing on unset variables
+func expandPath(path string) (string, error) {
+	expanded, err := cliBase.ExpandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	expanded = os.Expand(expanded, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+
+	return expanded, nil
+}
+
+// hasGlob checks whether a path segment contains glob metacharacters
+func hasGlob(segment string) bool {
+	return strings.ContainsAny(segment, "*?[")
+}
+
+// staticPrefix returns the leading segments of a path pattern that contain no glob
+func staticPrefix(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, segment := range segments {
+		if hasGlob(segment) {
+			return filepath.FromSlash(strings.Join(segments[:i], "/"))
+		}
+	}
+	return pattern
+}
+
+// globDirs returns the directories matching pattern, where ** matches any number of directories
+func globDirs(pattern string) ([]string, error) {
+	pattern = filepath.Clean(pattern)
+	base := staticPrefix(pattern)
+	if base == "" {
+		base = "."
+	}
+	rest := strings.TrimPrefix(filepath.ToSlash(pattern), filepath.ToSlash(base))
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+
+	seen := make(map[string]bool)
+	var matches []string
+
+	var match func(dir string, segments []string) error
+	match = func(dir string, segments []string) error {
+		if len(segments) == 0 {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() && !seen[dir] {
+				seen[dir] = true
+				matches = append(matches, dir)
+			}
+			return nil
+		}
+
+		segment := segments[0]
+		if segment == "**" {
+			if err := match(dir, segments[1:]); err != nil {
+				return err
+			}
+		} else if !hasGlob(segment) {
+			return match(filepath.Join(dir, segment), segments[1:])
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// unreadable directories simply don't match
+			return nil
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || entry.Name() == ".git" {
+				continue
+			}
+			if segment == "**" {
+				if err := match(filepath.Join(dir, entry.Name()), segments); err != nil {
+					return err
+				}
+				continue
+			}
+			matched, err := filepath.Match(segment, entry.Name())
+			if err != nil {
+				return err
+			}
+			if matched {
+				if err := match(filepath.Join(dir, entry.Name()), segments[1:]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := match(base, segments); err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// resolveRoots expands each configured path into the concrete directories to scan.
+// Every glob match becomes its own root.
+func resolveRoots(paths []string) []resolvedRoot {
+	var roots []resolvedRoot
+	for _, pattern := range paths {
+		expanded, err := expandPath(pattern)
+		if err != nil {
+			roots = append(roots, resolvedRoot{Pattern: pattern, Err: err})
+			continue
+		}
+
+		if !hasGlob(expanded) {
+			roots = append(roots, resolvedRoot{Pattern: pattern, Path: expanded})
+			continue
+		}
+
+		matches, err := globDirs(expanded)
+		if err == nil && len(matches) == 0 {
+			err = fmt.Errorf("no directories match %s", expanded)
+		}
+		if err != nil {
+			roots = append(roots, resolvedRoot{Pattern: pattern, Err: err})
+			continue
+		}
+		for _, match := range matches {
+			roots = append(roots, resolvedRoot{Pattern: pattern, Path: match})
+		}
+	}
+	return roots
+}
+
+// rootKeys identifies the resolved roots for cache validation,
+// so the cache is invalidated when a glob starts matching a new directory
+func rootKeys(roots []resolvedRoot) []string {
+	keys := make([]string, len(roots))
+	for i, root := range roots {
+		keys[i] = root.Path
+		if root.Err != nil {
+			keys[i] = root.Pattern
+		}
+	}
+	return keys
+}
+
+// joinWithin joins a slash-separated relative path from an untrusted source, such as a forge
+// listing or a manifest, onto root, rejecting absolute paths and ".." segments that would escape it
+func joinWithin(root, rel string) (string, error) {
+	if rel == "" || strings.HasPrefix(rel, "/") || filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" {
+		return "", fmt.Errorf("path '%s' must be relative", rel)
+	}
+	for _, segment := range strings.FieldsFunc(rel, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return "", fmt.Errorf("path '%s' must not contain '..'", rel)
+		}
+	}
+
+	joined := filepath.Join(root, filepath.FromSlash(rel))
+	inside, err := filepath.Rel(root, joined)
+	if err != nil || inside == "." || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path '%s' escapes %s", rel, root)
+	}
+	return joined, nil
+}
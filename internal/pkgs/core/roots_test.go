@@ -0,0 +1,212 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mkdirs(t *testing.T, base string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatalf("failed to create test directory %s: %v", dir, err)
+		}
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("failed to get home directory: %v", err)
+	}
+	t.Setenv("PROJECTS", "/mnt/projects")
+
+	tests := []struct {
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{path: "~/Git", expected: filepath.Join(home, "Git")},
+		{path: "$PROJECTS/code", expected: "/mnt/projects/code"},
+		{path: "${PROJECTS}/code", expected: "/mnt/projects/code"},
+		{path: "/plain/path", expected: "/plain/path"},
+		{path: "$REPO_SWITCHER_UNSET_VAR/code", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := expandPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("expandPath() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStaticPrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"~/work/*/src", "~/work"},
+		{"/mnt/projects/**/code", "/mnt/projects"},
+		{"/plain/path", "/plain/path"},
+		{"/a/b?", "/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if result := staticPrefix(tt.pattern); result != tt.expected {
+				t.Errorf("staticPrefix() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGlobDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	mkdirs(t, tempDir,
+		"work/a/src",
+		"work/b/src",
+		"work/c/docs",
+		"projects/code",
+		"projects/x/code",
+		"projects/x/y/code",
+		"projects/repo/.git/code",
+	)
+	if err := os.WriteFile(filepath.Join(tempDir, "work", "file"), nil, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []string
+	}{
+		{
+			name:    "single wildcard",
+			pattern: "work/*/src",
+			expected: []string{
+				filepath.Join(tempDir, "work/a/src"),
+				filepath.Join(tempDir, "work/b/src"),
+			},
+		},
+		{
+			name:    "recursive wildcard",
+			pattern: "projects/**/code",
+			expected: []string{
+				filepath.Join(tempDir, "projects/code"),
+				filepath.Join(tempDir, "projects/x/code"),
+				filepath.Join(tempDir, "projects/x/y/code"),
+			},
+		},
+		{
+			name:    "files are not roots",
+			pattern: "work/*",
+			expected: []string{
+				filepath.Join(tempDir, "work/a"),
+				filepath.Join(tempDir, "work/b"),
+				filepath.Join(tempDir, "work/c"),
+			},
+		},
+		{
+			name:     "no matches",
+			pattern:  "work/*/missing",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := globDirs(filepath.Join(tempDir, tt.pattern))
+			if err != nil {
+				t.Fatalf("globDirs() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("globDirs() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveRoots(t *testing.T) {
+	tempDir := t.TempDir()
+	mkdirs(t, tempDir, "work/a/src", "work/b/src")
+	t.Setenv("REPO_SWITCHER_TEST_ROOT", tempDir)
+
+	roots := resolveRoots([]string{
+		"$REPO_SWITCHER_TEST_ROOT/work/*/src",
+		"${REPO_SWITCHER_TEST_ROOT}/plain",
+		"$REPO_SWITCHER_TEST_ROOT/none/*",
+	})
+
+	if len(roots) != 4 {
+		t.Fatalf("resolveRoots() returned %d roots, want 4: %+v", len(roots), roots)
+	}
+	expectedPaths := []string{
+		filepath.Join(tempDir, "work/a/src"),
+		filepath.Join(tempDir, "work/b/src"),
+		filepath.Join(tempDir, "plain"),
+	}
+	for i, expected := range expectedPaths {
+		if roots[i].Path != expected || roots[i].Err != nil {
+			t.Errorf("resolveRoots()[%d] = %+v, want path %s", i, roots[i], expected)
+		}
+	}
+	if roots[0].Pattern != "$REPO_SWITCHER_TEST_ROOT/work/*/src" {
+		t.Errorf("resolveRoots() pattern = %s, want configured pattern", roots[0].Pattern)
+	}
+	if roots[3].Err == nil {
+		t.Error("resolveRoots() expected error for glob without matches, got nil")
+	}
+}
+
+func TestRootKeysChangeWithGlobMatches(t *testing.T) {
+	tempDir := t.TempDir()
+	mkdirs(t, tempDir, "work/a")
+	paths := []string{filepath.Join(tempDir, "work/*")}
+
+	before := hashPaths(rootKeys(resolveRoots(paths)))
+	mkdirs(t, tempDir, "work/b")
+	after := hashPaths(rootKeys(resolveRoots(paths)))
+
+	if before == after {
+		t.Error("rootKeys() hash did not change when a glob matched a new directory")
+	}
+}
+
+func TestListGitReposGlobRoots(t *testing.T) {
+	tempDir := t.TempDir()
+	mkdirs(t, tempDir, "work/a/src/repo1/.git", "work/b/src/repo2/.git", "work/c/other/repo3/.git")
+
+	repos, report := listGitRepos([]string{filepath.Join(tempDir, "work/*/src")}, false)
+
+	if len(report.Roots) != 2 {
+		t.Errorf("listGitRepos() reported %d roots, want 2", len(report.Roots))
+	}
+	expected := []string{
+		filepath.Join(tempDir, "work/a/src/repo1"),
+		filepath.Join(tempDir, "work/b/src/repo2"),
+	}
+	if !reflect.DeepEqual(repoPaths(repos), expected) {
+		t.Errorf("listGitRepos() = %v, want %v", repos, expected)
+	}
+}
+
+func TestJoinWithin(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "root")
+
+	if joined, err := joinWithin(root, "github.com/kahnwong/tool"); err != nil || joined != filepath.Join(root, "github.com", "kahnwong", "tool") {
+		t.Errorf("joinWithin() = %s, %v", joined, err)
+	}
+	for _, rel := range []string{"", ".", "../x", "github.com/../../x", "a/..", "/etc/passwd", `a\..\..\x`} {
+		if joined, err := joinWithin(root, rel); err == nil {
+			t.Errorf("joinWithin(%q) = %s, want error", rel, joined)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// inGroup checks whether a repo path matches any of the group's glob patterns
+func inGroup(repoPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		expanded, err := expandPath(pattern)
+		if err != nil {
+			continue
+		}
+		if matched, _ := filepath.Match(expanded, repoPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedRepo is a repo picked for a bulk command, Name labelling it in output
+type SelectedRepo struct {
+	Name string
+	Path string
+}
+
+// selectionLabel names a repo in bulk output: its display name, or its path when another repo took that name
+func selectionLabel(repo Repo, name string) string {
+	if ReposMap[name] == repo.Path {
+		return name
+	}
+	return repo.Path
+}
+
+// SelectRepos returns the indexed repos in the given group whose name matches filter, sorted by label.
+// Repos whose name is shadowed by another are included too. An empty group or filter matches everything.
+func SelectRepos(group, filter string) ([]SelectedRepo, error) {
+	var patterns []string
+	if group != "" {
+		var ok bool
+		if AppConfig != nil {
+			patterns, ok = AppConfig.Groups[group]
+		}
+		if !ok {
+			return nil, fmt.Errorf("group '%s' is not defined in config", group)
+		}
+	}
+
+	if filter != "" {
+		if _, err := filepath.Match(filter, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter '%s': %w", filter, err)
+		}
+	}
+
+	mode := naming()
+	var selected []SelectedRepo
+	for _, repo := range indexedRepos {
+		if group != "" && !inGroup(repo.Path, patterns) {
+			continue
+		}
+		name := repoName(repo, mode)
+		if filter != "" {
+			if matched, _ := filepath.Match(filter, name); !matched {
+				continue
+			}
+		}
+		selected = append(selected, SelectedRepo{Name: selectionLabel(repo, name), Path: repo.Path})
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Name != selected[j].Name {
+			return selected[i].Name < selected[j].Name
+		}
+		return selected[i].Path < selected[j].Path
+	})
+
+	return selected, nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectRepos(t *testing.T) {
+	originalReposMap := ReposMap
+	originalIndexedRepos := indexedRepos
+	originalAppConfig := AppConfig
+	defer func() {
+		ReposMap = originalReposMap
+		indexedRepos = originalIndexedRepos
+		AppConfig = originalAppConfig
+	}()
+
+	// the second api is shadowed by the first but still selected, labelled by its path
+	indexedRepos = []Repo{
+		{Path: "/src/work/api"},
+		{Path: "/src/work/api-tools"},
+		{Path: "/src/personal/dotfiles"},
+		{Path: "/src/old/api"},
+	}
+	ReposMap = map[string]string{
+		"api":       "/src/work/api",
+		"api-tools": "/src/work/api-tools",
+		"dotfiles":  "/src/personal/dotfiles",
+	}
+	AppConfig = &Config{
+		Groups: map[string][]string{
+			"work": {"/src/work/*"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		group    string
+		filter   string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "no group or filter",
+			expected: []string{"/src/old/api", "api", "api-tools", "dotfiles"},
+		},
+		{
+			name:     "group only",
+			group:    "work",
+			expected: []string{"api", "api-tools"},
+		},
+		{
+			name:     "filter only",
+			filter:   "api*",
+			expected: []string{"/src/old/api", "api", "api-tools"},
+		},
+		{
+			name:     "group and filter",
+			group:    "work",
+			filter:   "*-tools",
+			expected: []string{"api-tools"},
+		},
+		{
+			name:    "unknown group",
+			group:   "missing",
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter",
+			filter:  "[",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SelectRepos(tt.group, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectRepos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, repo := range result {
+				names = append(names, repo.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("SelectRepos() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SortAlphabetical orders names by byte order (default)
+	SortAlphabetical = "alphabetical"
+	// SortFrecency favours repos switched to often and recently, from the switch history
+	SortFrecency = "frecency"
+	// SortCommit orders by the last movement of HEAD, most recent first
+	SortCommit = "commit"
+	// SortModified orders by the last change to the index, a cheap stand-in for the working tree
+	SortModified = "mtime"
+)
+
+// RepoActivity holds timestamps read from files under .git at scan time
+type RepoActivity struct {
+	Commit   time.Time `json:"commit,omitzero"`
+	Modified time.Time `json:"modified,omitzero"`
+}
+
+// sortStrategy returns the configured sort strategy
+func sortStrategy() string {
+	if AppConfig == nil || AppConfig.Sort == "" {
+		return SortAlphabetical
+	}
+	return AppConfig.Sort
+}
+
+// KeepsOrder reports whether names come in an order of their own that shells should not re-sort
+func KeepsOrder() bool {
+	return sortStrategy() != SortAlphabetical
+}
+
+// validateSort rejects unknown sort strategies
+func validateSort(strategy string) error {
+	switch strategy {
+	case "", SortAlphabetical, SortFrecency, SortCommit, SortModified:
+		return nil
+	}
+	return fmt.Errorf("invalid sort '%s', expected %s, %s, %s or %s", strategy, SortAlphabetical, SortFrecency, SortCommit, SortModified)
+}
+
+// gitDir returns the git directory of the repo at path, following the "gitdir:" file of worktrees and submodules
+func gitDir(path string) string {
+	dotGit := filepath.Join(path, ".git")
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+	dir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir
+}
+
+// modTime returns the modification time of the first of files that exists
+func modTime(files ...string) time.Time {
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
+
+// readActivity stats a few files under .git, so it costs no git process per repo
+func readActivity(path string) *RepoActivity {
+	dir := gitDir(path)
+	activity := &RepoActivity{
+		// the HEAD reflog is appended on every commit, checkout and pull
+		Commit:   modTime(filepath.Join(dir, "logs", "HEAD"), filepath.Join(dir, "HEAD")),
+		Modified: modTime(filepath.Join(dir, "index")),
+	}
+	if activity.Commit.IsZero() && activity.Modified.IsZero() {
+		return nil
+	}
+	return activity
+}
+
+// frecencyScores weighs every switch in the history by how recent it was, summing per repo path
+func frecencyScores(entries []HistoryEntry, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, entry := range entries {
+		age := now.Sub(entry.Time)
+		var weight float64
+		switch {
+		case age < time.Hour:
+			weight = 4
+		case age < 24*time.Hour:
+			weight = 2
+		case age < 7*24*time.Hour:
+			weight = 1
+		default:
+			weight = 0.5
+		}
+		scores[entry.Path] += weight
+	}
+	return scores
+}
+
+// sortNames orders display names by strategy, most relevant first, breaking ties alphabetically
+func sortNames(names []string, reposMap map[string]string, repos []Repo, strategy string) []string {
+	var score func(path string) float64
+	switch strategy {
+	case SortFrecency:
+		entries, _ := readHistoryFile()
+		scores := frecencyScores(entries, time.Now())
+		score = func(path string) float64 { return scores[path] }
+	case SortCommit, SortModified:
+		byPath := make(map[string]*RepoActivity, len(repos))
+		for _, repo := range repos {
+			byPath[repo.Path] = repo.Activity
+		}
+		score = func(path string) float64 {
+			activity := byPath[path]
+			if activity == nil {
+				return math.Inf(-1)
+			}
+			if strategy == SortCommit {
+				return float64(activity.Commit.UnixNano())
+			}
+			return float64(activity.Modified.UnixNano())
+		}
+	}
+
+	if score == nil {
+		sort.Strings(names)
+		return names
+	}
+
+	scores := make(map[string]float64, len(names))
+	for _, name := range names {
+		scores[name] = score(reposMap[name])
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if scores[names[i]] != scores[names[j]] {
+			return scores[names[i]] > scores[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateSort(t *testing.T) {
+	for _, strategy := range []string{"", SortAlphabetical, SortFrecency, SortCommit, SortModified} {
+		if err := validateSort(strategy); err != nil {
+			t.Errorf("validateSort(%q) error = %v", strategy, err)
+		}
+	}
+	if err := validateSort("random"); err == nil {
+		t.Error("validateSort(\"random\") should fail")
+	}
+}
+
+func TestReadActivity(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "repo/.git/logs", "bare/.git", "worktree", "store/wt")
+
+	commit := time.Now().Add(-time.Hour).Truncate(time.Second)
+	modified := time.Now().Add(-time.Minute).Truncate(time.Second)
+	touch := func(path string, at time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(filepath.Join(root, "repo/.git/logs/HEAD"), commit)
+	touch(filepath.Join(root, "repo/.git/index"), modified)
+
+	activity := readActivity(filepath.Join(root, "repo"))
+	if activity == nil || !activity.Commit.Equal(commit) || !activity.Modified.Equal(modified) {
+		t.Errorf("readActivity() = %+v, want commit %s and modified %s", activity, commit, modified)
+	}
+
+	if activity := readActivity(filepath.Join(root, "bare")); activity != nil {
+		t.Errorf("readActivity() without git files = %+v, want nil", activity)
+	}
+
+	// worktrees point at their git directory through a .git file
+	if err := os.WriteFile(filepath.Join(root, "worktree/.git"), []byte("gitdir: ../store/wt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	touch(filepath.Join(root, "store/wt/HEAD"), commit)
+	if activity := readActivity(filepath.Join(root, "worktree")); activity == nil || !activity.Commit.Equal(commit) {
+		t.Errorf("readActivity() for worktree = %+v, want commit from HEAD", activity)
+	}
+}
+
+func TestFrecencyScores(t *testing.T) {
+	now := time.Now()
+	scores := frecencyScores([]HistoryEntry{
+		{Path: "/a", Time: now.Add(-10 * time.Minute)},
+		{Path: "/b", Time: now.Add(-30 * 24 * time.Hour)},
+		{Path: "/b", Time: now.Add(-40 * 24 * time.Hour)},
+		{Path: "/c", Time: now.Add(-2 * time.Hour)},
+		{Path: "/c", Time: now.Add(-3 * 24 * time.Hour)},
+	}, now)
+
+	expected := map[string]float64{"/a": 4, "/b": 1, "/c": 3}
+	if !reflect.DeepEqual(scores, expected) {
+		t.Errorf("frecencyScores() = %v, want %v", scores, expected)
+	}
+}
+
+func TestSortNames(t *testing.T) {
+	setupHistory(t)
+	now := time.Now()
+	repos := []Repo{
+		{Path: "/src/old", Activity: &RepoActivity{Commit: now.Add(-48 * time.Hour), Modified: now.Add(-time.Minute)}},
+		{Path: "/src/new", Activity: &RepoActivity{Commit: now.Add(-time.Hour), Modified: now.Add(-time.Hour)}},
+		{Path: "/src/none"},
+		{Path: "/src/also-none"},
+	}
+	reposMap := map[string]string{"old": "/src/old", "new": "/src/new", "none": "/src/none", "also-none": "/src/also-none"}
+
+	if err := os.MkdirAll(filepath.Dir(historyFilePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeHistoryFile([]HistoryEntry{
+		{Path: "/src/none", Time: now.Add(-time.Minute)},
+		{Path: "/src/old", Time: now.Add(-3 * time.Hour)},
+		{Path: "/src/none", Time: now.Add(-2 * time.Minute)},
+	}); err != nil {
+		t.Fatalf("writeHistoryFile() error = %v", err)
+	}
+
+	tests := map[string][]string{
+		SortAlphabetical: {"also-none", "new", "none", "old"},
+		SortCommit:       {"new", "old", "also-none", "none"},
+		SortModified:     {"old", "new", "also-none", "none"},
+		SortFrecency:     {"none", "old", "also-none", "new"},
+	}
+	for strategy, expected := range tests {
+		names := sortNames([]string{"old", "none", "new", "also-none"}, reposMap, repos, strategy)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("sortNames(%s) = %v, want %v", strategy, names, expected)
+		}
+	}
+}
+
+func TestCompleteNamesKeepsSortOrder(t *testing.T) {
+	now := time.Now()
+	setupNamedRepos(t, NamingRelative, nil)
+	AppConfig.Sort = SortCommit
+	setRepos([]Repo{
+		{Path: "/src/a/old", Root: "/src", Activity: &RepoActivity{Commit: now.Add(-time.Hour)}},
+		{Path: "/src/b/new", Root: "/src", Activity: &RepoActivity{Commit: now}},
+	})
+
+	if candidates, _ := completeNames(ReposName, nil, true, KeepsOrder(), ""); !reflect.DeepEqual(candidates, []string{"b/", "a/"}) {
+		t.Errorf("completeNames() = %v, want most recent first", candidates)
+	}
+	if !KeepsOrder() {
+		t.Error("KeepsOrder() = false for commit sort")
+	}
+}
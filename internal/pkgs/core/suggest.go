@@ -0,0 +1,98 @@
+package core
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions caps the number of "did you mean" candidates
+const maxSuggestions = 5
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// isSubsequence checks whether every rune of query appears in name in order
+func isSubsequence(query, name string) bool {
+	rn := []rune(name)
+	i := 0
+	for _, r := range query {
+		for i < len(rn) && rn[i] != r {
+			i++
+		}
+		if i == len(rn) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+type suggestion struct {
+	name     string
+	tier     int
+	distance int
+}
+
+// Suggest returns the repo names closest to a name that was not found, best first.
+// Substring matches rank first, then fuzzy subsequence matches, then names within a small edit distance.
+func Suggest(query string) []string {
+	query = strings.ToLower(query)
+	// a distance as long as the query means nothing was shared
+	threshold := min(max(2, len([]rune(query))/3), len([]rune(query))-1)
+
+	var suggestions []suggestion
+	for _, name := range ReposName {
+		lower := strings.ToLower(name)
+		distance := min(levenshtein(query, lower), levenshtein(query, path.Base(lower)))
+
+		var tier int
+		switch {
+		case strings.Contains(lower, query):
+			tier = 0
+		case isSubsequence(query, lower):
+			tier = 1
+		case distance <= threshold:
+			tier = 2
+		default:
+			continue
+		}
+		suggestions = append(suggestions, suggestion{name: name, tier: tier, distance: distance})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.name < b.name
+	})
+
+	names := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		names = append(names, suggestions[i].name)
+	}
+	return names
+}
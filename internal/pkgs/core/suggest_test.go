@@ -0,0 +1,80 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"repo", "repo", 0},
+		{"repo-switcher", "repo-swticher", 2},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if result := levenshtein(tt.a, tt.b); result != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestIsSubsequence(t *testing.T) {
+	tests := []struct {
+		query, name string
+		expected    bool
+	}{
+		{"rs", "repo-switcher", true},
+		{"rpsw", "repo-switcher", true},
+		{"sr", "repo-switcher", true},
+		{"xyz", "repo-switcher", false},
+		{"", "anything", true},
+	}
+
+	for _, tt := range tests {
+		if result := isSubsequence(tt.query, tt.name); result != tt.expected {
+			t.Errorf("isSubsequence(%q, %q) = %v, want %v", tt.query, tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	originalReposName := ReposName
+	defer func() { ReposName = originalReposName }()
+	ReposName = []string{"repo-switcher", "dotfiles", "api", "api-gateway", "terraform", "work/infra/terraform"}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"repo-swticher", []string{"repo-switcher"}},
+		{"API", []string{"api", "api-gateway"}},
+		{"terrafrom", []string{"terraform", "work/infra/terraform"}},
+		{"dtf", []string{"dotfiles"}},
+		{"zzzzzz", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if result := Suggest(tt.query); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSuggestLimit(t *testing.T) {
+	originalReposName := ReposName
+	defer func() { ReposName = originalReposName }()
+	ReposName = []string{"a1", "a2", "a3", "a4", "a5", "a6", "a7"}
+
+	if result := Suggest("a"); len(result) != maxSuggestions {
+		t.Errorf("Suggest() returned %d names, want %d", len(result), maxSuggestions)
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type SweepKind string
+
+const (
+	SweepUncommitted SweepKind = "uncommitted"
+	SweepUntracked   SweepKind = "untracked"
+	SweepStash       SweepKind = "stash"
+	SweepUnpushed    SweepKind = "unpushed"
+	SweepNoUpstream  SweepKind = "no-upstream"
+)
+
+type SweepFinding struct {
+	Kind   SweepKind
+	Detail string
+}
+
+type SweepResult struct {
+	Name     string
+	Path     string
+	Findings []SweepFinding
+	Err      error
+}
+
+// sweepRepo collects all local work in dir that only exists on this machine
+func sweepRepo(ctx context.Context, dir string) ([]SweepFinding, error) {
+	var findings []SweepFinding
+
+	status, err := runGit(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var changed, untracked int
+	for _, line := range strings.Split(status, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "??"):
+			untracked++
+		default:
+			changed++
+		}
+	}
+	if changed > 0 {
+		findings = append(findings, SweepFinding{SweepUncommitted, fmt.Sprintf("%d changed file(s)", changed)})
+	}
+	if untracked > 0 {
+		findings = append(findings, SweepFinding{SweepUntracked, fmt.Sprintf("%d untracked file(s)", untracked)})
+	}
+
+	stashes, err := runGit(ctx, dir, "stash", "list")
+	if err != nil {
+		return nil, err
+	}
+	if stashes != "" {
+		findings = append(findings, SweepFinding{SweepStash, fmt.Sprintf("%d stash(es)", strings.Count(stashes, "\n")+1)})
+	}
+
+	// fields are NUL separated since runGit trims the output, which would eat empty trailing tab-separated fields
+	branches, err := runGit(ctx, dir, "for-each-ref", "--format=%(refname:short)%00%(upstream:short)%00%(upstream:track)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(branches, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		branch, upstream, track := fields[0], fields[1], fields[2]
+
+		if upstream == "" || track == "[gone]" {
+			findings = append(findings, SweepFinding{SweepNoUpstream, branch})
+			continue
+		}
+
+		var ahead int
+		if i := strings.Index(track, "ahead "); i >= 0 {
+			if _, err := fmt.Sscanf(track[i:], "ahead %d", &ahead); err == nil && ahead > 0 {
+				findings = append(findings, SweepFinding{SweepUnpushed, fmt.Sprintf("%s: %d commit(s) ahead of %s", branch, ahead, upstream)})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// SweepRepos checks each selected repo for unpushed or uncommitted work with bounded parallelism.
+// Results are returned in the same order as repos.
+func SweepRepos(ctx context.Context, repos []SelectedRepo, jobs int) []SweepResult {
+	results := make([]SweepResult, len(repos))
+
+	forEachParallel(ctx, len(repos), jobs, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Name, result.Path = repos[i].Name, repos[i].Path
+		result.Findings, result.Err = sweepRepo(ctx, result.Path)
+	})
+
+	return results
+}
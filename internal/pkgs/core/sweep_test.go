@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sweepKinds(findings []SweepFinding) map[SweepKind]bool {
+	kinds := make(map[SweepKind]bool)
+	for _, f := range findings {
+		kinds[f.Kind] = true
+	}
+	return kinds
+}
+
+func TestSweepRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, clone string)
+		expected []SweepKind
+	}{
+		{
+			name:  "clean",
+			setup: func(t *testing.T, clone string) {},
+		},
+		{
+			name: "uncommitted changes",
+			setup: func(t *testing.T, clone string) {
+				if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("changed\n"), 0644); err != nil {
+					t.Fatalf("failed to modify file: %v", err)
+				}
+			},
+			expected: []SweepKind{SweepUncommitted},
+		},
+		{
+			name: "untracked files",
+			setup: func(t *testing.T, clone string) {
+				if err := os.WriteFile(filepath.Join(clone, "notes.txt"), []byte("notes\n"), 0644); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			},
+			expected: []SweepKind{SweepUntracked},
+		},
+		{
+			name: "stash",
+			setup: func(t *testing.T, clone string) {
+				if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("changed\n"), 0644); err != nil {
+					t.Fatalf("failed to modify file: %v", err)
+				}
+				gitCmd(t, clone, "stash", "-q")
+			},
+			expected: []SweepKind{SweepStash},
+		},
+		{
+			name: "unpushed commits",
+			setup: func(t *testing.T, clone string) {
+				commitFile(t, clone, "local.txt", "local\n")
+			},
+			expected: []SweepKind{SweepUnpushed},
+		},
+		{
+			name: "branch without upstream",
+			setup: func(t *testing.T, clone string) {
+				gitCmd(t, clone, "branch", "feature")
+			},
+			expected: []SweepKind{SweepNoUpstream},
+		},
+		{
+			name: "branch without upstream sorting last",
+			setup: func(t *testing.T, clone string) {
+				gitCmd(t, clone, "branch", "zzz")
+			},
+			expected: []SweepKind{SweepNoUpstream},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, clone := newRemoteAndClone(t)
+			tt.setup(t, clone)
+
+			findings, err := sweepRepo(context.Background(), clone)
+			if err != nil {
+				t.Fatalf("sweepRepo() error = %v", err)
+			}
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("sweepRepo() = %+v, want kinds %v", findings, tt.expected)
+			}
+			kinds := sweepKinds(findings)
+			for _, kind := range tt.expected {
+				if !kinds[kind] {
+					t.Errorf("sweepRepo() missing finding %s, got %+v", kind, findings)
+				}
+			}
+		})
+	}
+}
+
+func TestSweepReposNotARepo(t *testing.T) {
+	requireGit(t)
+	results := SweepRepos(context.Background(), []SelectedRepo{{Name: "plain", Path: t.TempDir()}}, 1)
+	if results[0].Err == nil {
+		t.Error("SweepRepos() expected error for non-git directory, got nil")
+	}
+}
+
+func TestSweepReposShadowedName(t *testing.T) {
+	remote, shadowed := newRemoteAndClone(t)
+	visible := cloneRemote(t, remote)
+	if err := os.WriteFile(filepath.Join(shadowed, "notes.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalReposMap, originalIndexedRepos := ReposMap, indexedRepos
+	defer func() { ReposMap, indexedRepos = originalReposMap, originalIndexedRepos }()
+	// both clones are named "clone", the later one wins the name
+	indexedRepos = []Repo{{Path: shadowed}, {Path: visible}}
+	ReposMap = map[string]string{"clone": visible}
+
+	repos, err := SelectRepos("", "")
+	if err != nil {
+		t.Fatalf("SelectRepos() error = %v", err)
+	}
+	results := SweepRepos(context.Background(), repos, 2)
+	if len(results) != 2 {
+		t.Fatalf("SweepRepos() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if dirty := len(r.Findings) > 0; dirty != (r.Path == shadowed) {
+			t.Errorf("SweepRepos() %s findings = %+v", r.Name, r.Findings)
+		}
+	}
+}
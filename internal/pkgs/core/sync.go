@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+)
+
+type SyncStatus string
+
+const (
+	SyncUpdated    SyncStatus = "updated"
+	SyncUpToDate   SyncStatus = "up-to-date"
+	SyncDirty      SyncStatus = "skipped (dirty)"
+	SyncNoUpstream SyncStatus = "skipped (no upstream)"
+	SyncDiverged   SyncStatus = "diverged"
+	SyncFailed     SyncStatus = "failed"
+)
+
+type SyncResult struct {
+	Name   string
+	Path   string
+	Status SyncStatus
+	Err    error
+}
+
+// syncRepo fetches all remotes and fast-forwards the current branch if it is safe to do so
+func syncRepo(ctx context.Context, dir string) (SyncStatus, error) {
+	if _, err := runGit(ctx, dir, "fetch", "--all", "--quiet"); err != nil {
+		return SyncFailed, err
+	}
+
+	// untracked files are left alone by a fast-forward, git refuses if one would be overwritten
+	status, err := runGit(ctx, dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return SyncFailed, err
+	}
+	if status != "" {
+		return SyncDirty, nil
+	}
+
+	if !hasUpstream(ctx, dir) {
+		return SyncNoUpstream, nil
+	}
+
+	ahead, behind, err := aheadBehind(ctx, dir)
+	if err != nil {
+		return SyncFailed, err
+	}
+	switch {
+	case behind == 0:
+		return SyncUpToDate, nil
+	case ahead > 0:
+		return SyncDiverged, nil
+	}
+
+	if _, err := runGit(ctx, dir, "merge", "--ff-only", "--quiet", "@{u}"); err != nil {
+		return SyncFailed, err
+	}
+
+	return SyncUpdated, nil
+}
+
+// SyncRepos fetches and fast-forwards each selected repo with bounded parallelism.
+// Results are returned in the same order as repos.
+func SyncRepos(ctx context.Context, repos []SelectedRepo, jobs int) []SyncResult {
+	results := make([]SyncResult, len(repos))
+
+	forEachParallel(ctx, len(repos), jobs, func(ctx context.Context, i int) {
+		result := &results[i]
+		result.Name, result.Path = repos[i].Name, repos[i].Path
+		result.Status, result.Err = syncRepo(ctx, result.Path)
+	})
+
+	return results
+}
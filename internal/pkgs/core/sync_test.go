@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, remote, clone string)
+		expected SyncStatus
+	}{
+		{
+			name:     "up to date",
+			setup:    func(t *testing.T, remote, clone string) {},
+			expected: SyncUpToDate,
+		},
+		{
+			name: "behind upstream",
+			setup: func(t *testing.T, remote, clone string) {
+				other := cloneRemote(t, remote)
+				commitFile(t, other, "new.txt", "new\n")
+				gitCmd(t, other, "push", "-q")
+			},
+			expected: SyncUpdated,
+		},
+		{
+			name: "dirty working tree",
+			setup: func(t *testing.T, remote, clone string) {
+				other := cloneRemote(t, remote)
+				commitFile(t, other, "new.txt", "new\n")
+				gitCmd(t, other, "push", "-q")
+
+				if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("changed\n"), 0644); err != nil {
+					t.Fatalf("failed to modify file: %v", err)
+				}
+			},
+			expected: SyncDirty,
+		},
+		{
+			name: "diverged",
+			setup: func(t *testing.T, remote, clone string) {
+				other := cloneRemote(t, remote)
+				commitFile(t, other, "new.txt", "new\n")
+				gitCmd(t, other, "push", "-q")
+
+				commitFile(t, clone, "local.txt", "local\n")
+			},
+			expected: SyncDiverged,
+		},
+		{
+			name: "no upstream",
+			setup: func(t *testing.T, remote, clone string) {
+				gitCmd(t, clone, "checkout", "-q", "-b", "feature")
+			},
+			expected: SyncNoUpstream,
+		},
+		{
+			name: "fetch fails",
+			setup: func(t *testing.T, remote, clone string) {
+				gitCmd(t, clone, "remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+			},
+			expected: SyncFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote, clone := newRemoteAndClone(t)
+			tt.setup(t, remote, clone)
+			headBefore := gitCmd(t, clone, "rev-parse", "HEAD")
+
+			status, err := syncRepo(context.Background(), clone)
+			if status != tt.expected {
+				t.Errorf("syncRepo() = %s (err %v), want %s", status, err, tt.expected)
+			}
+
+			headAfter := gitCmd(t, clone, "rev-parse", "HEAD")
+			if tt.expected != SyncUpdated && headBefore != headAfter {
+				t.Errorf("syncRepo() moved HEAD for status %s", status)
+			}
+			if tt.expected == SyncUpdated && gitCmd(t, clone, "rev-parse", "@{u}") != headAfter {
+				t.Error("syncRepo() did not fast-forward to upstream")
+			}
+		})
+	}
+}
+
+func TestSyncRepos(t *testing.T) {
+	remote, clone := newRemoteAndClone(t)
+	other := cloneRemote(t, remote)
+	commitFile(t, other, "new.txt", "new\n")
+	gitCmd(t, other, "push", "-q")
+
+	results := SyncRepos(context.Background(), []SelectedRepo{{Name: "clone", Path: clone}, {Name: "other", Path: other}}, 2)
+
+	if results[0].Name != "clone" || results[0].Status != SyncUpdated {
+		t.Errorf("SyncRepos() clone = %+v, want %s", results[0], SyncUpdated)
+	}
+	if results[1].Name != "other" || results[1].Status != SyncUpToDate {
+		t.Errorf("SyncRepos() other = %+v, want %s", results[1], SyncUpToDate)
+	}
+}
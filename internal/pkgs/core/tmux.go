@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TmuxWindow is one window of a session layout
+type TmuxWindow struct {
+	Name string `yaml:"name"`
+	// Command runs when the window opens, then an interactive shell takes over
+	Command string `yaml:"command,omitempty"`
+}
+
+// tmuxSocket selects a tmux server by socket name, empty for the default server
+var tmuxSocket string
+
+// tmuxCommand builds a tmux command against the selected server
+func tmuxCommand(args ...string) *exec.Cmd {
+	if tmuxSocket != "" {
+		args = append([]string{"-L", tmuxSocket}, args...)
+	}
+	return exec.Command("tmux", args...)
+}
+
+// runTmux runs tmux and returns its trimmed stdout
+func runTmux(args ...string) (string, error) {
+	cmd := tmuxCommand(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("tmux %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("tmux %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// TmuxSessionName turns a repo name into a session name; tmux reserves "." and ":" in targets
+func TmuxSessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// sessionLayout returns the layout of the first group, in name order, that contains the repo and has one,
+// falling back to a single window running the repo's declared open command
+func sessionLayout(repoPath string) []TmuxWindow {
+	if AppConfig != nil {
+		for _, group := range repoGroups(repoPath) {
+			if layout, ok := AppConfig.Layouts[group]; ok {
+				return layout
+			}
+		}
+	}
+	if repo := indexedRepo(repoPath); repo != nil && repo.Meta != nil && repo.Meta.Open != "" {
+		return []TmuxWindow{{Command: repo.Meta.Open}}
+	}
+	return nil
+}
+
+// EnsureSession creates a detached session rooted at repoPath unless one with that name exists,
+// laying out windows from the repo's group. It reports whether the session was created.
+func EnsureSession(session, repoPath string) (bool, error) {
+	// "=" makes tmux match the name exactly instead of by prefix
+	if _, err := runTmux("has-session", "-t", "="+session); err == nil {
+		return false, nil
+	}
+
+	layout := sessionLayout(repoPath)
+	if len(layout) == 0 {
+		layout = []TmuxWindow{{}}
+	}
+
+	for i, window := range layout {
+		args := []string{"new-window", "-d", "-t", "=" + session + ":"}
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", session}
+		}
+		args = append(args, "-c", repoPath)
+		if window.Name != "" {
+			args = append(args, "-n", window.Name)
+		}
+		if window.Command != "" {
+			// typing the command with send-keys races the shell's startup, so run it before the shell instead
+			args = append(args, window.Command+`; exec "${SHELL:-/bin/sh}"`)
+		}
+		if _, err := runTmux(args...); err != nil {
+			return i > 0, err
+		}
+	}
+
+	return true, nil
+}
+
+// AttachSession switches the current client to session inside tmux, or attaches the terminal to it outside
+func AttachSession(session string) error {
+	verb := "attach-session"
+	if os.Getenv("TMUX") != "" {
+		verb = "switch-client"
+	}
+	cmd := tmuxCommand(verb, "-t", "="+session)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupTmux points tmux at a private server that is killed when the test ends
+func setupTmux(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+
+	original := tmuxSocket
+	tmuxSocket = fmt.Sprintf("repo-switcher-test-%d", time.Now().UnixNano())
+	t.Setenv("TMUX", "")
+	// a plain shell starts without rc files that could swallow keys sent by layouts
+	t.Setenv("SHELL", "/bin/sh")
+	t.Cleanup(func() {
+		_, _ = runTmux("kill-server")
+		tmuxSocket = original
+	})
+}
+
+func TestTmuxSessionName(t *testing.T) {
+	tests := map[string]string{
+		"repo-switcher":     "repo-switcher",
+		"kahnwong/dotfiles": "kahnwong/dotfiles",
+		"example.com":       "example_com",
+		"a:b":               "a_b",
+	}
+	for name, expected := range tests {
+		if result := TmuxSessionName(name); result != expected {
+			t.Errorf("TmuxSessionName(%q) = %q, want %q", name, result, expected)
+		}
+	}
+}
+
+func TestEnsureSession(t *testing.T) {
+	setupTmux(t)
+	setupNamedRepos(t, NamingBasename, nil)
+	repoPath := t.TempDir()
+
+	created, err := EnsureSession("api", repoPath)
+	if err != nil || !created {
+		t.Fatalf("EnsureSession() = %v, %v, want created", created, err)
+	}
+	if path, err := runTmux("display-message", "-p", "-t", "=api:", "#{pane_current_path}"); err != nil || path != repoPath {
+		t.Errorf("session path = %q, %v, want %s", path, err, repoPath)
+	}
+
+	created, err = EnsureSession("api", repoPath)
+	if err != nil || created {
+		t.Errorf("EnsureSession() on existing session = %v, %v, want reused", created, err)
+	}
+
+	// "=" keeps a new session from matching an existing one by prefix
+	if created, err := EnsureSession("ap", repoPath); err != nil || !created {
+		t.Errorf("EnsureSession() for prefix of existing session = %v, %v, want created", created, err)
+	}
+}
+
+func TestEnsureSessionLayout(t *testing.T) {
+	setupTmux(t)
+	root := t.TempDir()
+	repoPath := filepath.Join(root, "work", "api")
+	mkdirs(t, root, "work/api")
+	// the command records its working directory outside the repo, which must stay untouched
+	marker := filepath.Join(t.TempDir(), "pwd")
+
+	setupNamedRepos(t, NamingBasename, nil)
+	AppConfig.Groups = map[string][]string{"work": {filepath.Join(root, "work", "*")}}
+	AppConfig.Layouts = map[string][]TmuxWindow{"work": {
+		{Name: "editor"},
+		{Name: "shell", Command: "pwd > '" + marker + "'"},
+	}}
+
+	if _, err := EnsureSession("api", repoPath); err != nil {
+		t.Fatalf("EnsureSession() error = %v", err)
+	}
+
+	windows, err := runTmux("list-windows", "-t", "=api", "-F", "#{window_name}")
+	if err != nil {
+		t.Fatalf("list-windows error = %v", err)
+	}
+	if names := strings.Fields(windows); strings.Join(names, ",") != "editor,shell" {
+		t.Errorf("windows = %v, want editor,shell", names)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, err := os.ReadFile(marker); err == nil && strings.TrimSpace(string(data)) == repoPath {
+			break
+		}
+		if time.Now().After(deadline) {
+			pane, _ := runTmux("capture-pane", "-p", "-t", "=api:shell")
+			t.Fatalf("layout command did not run in the repo:\n%s", pane)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"os"
+
 	"github.com/kahnwong/repo-switcher/cmd"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	if err := cmd.RootCmd.Execute(); err != nil {
-		log.Fatal().Err(err).Msg("command execution failed")
+	if err := cmd.Execute(); err != nil {
+		log.Error().Err(err).Msg("command execution failed")
+		os.Exit(cmd.ExitCode(err))
 	}
 }